@@ -31,6 +31,8 @@ const (
 
 	defaultQPS   = 50.0
 	defaultBurst = 100
+
+	defaultMaxSessionRecords = 100
 )
 
 // ServerOption is the main context object for the controller manager.
@@ -44,10 +46,14 @@ type ServerOption struct {
 	LockObjectNamespace  string
 	DefaultQueue         string
 	PrintVersion         bool
+	ValidateConfig       bool
 	ListenAddress        string
 	EnablePriorityClass  bool
 	KubeAPIBurst         int
 	KubeAPIQPS           float32
+	SessionRecordDir     string
+	MaxSessionRecords    int
+	EnableExplanations   bool
 }
 
 // ServerOpts server options
@@ -72,12 +78,18 @@ func (s *ServerOption) AddFlags(fs *pflag.FlagSet) {
 		"Start a leader election client and gain leadership before "+
 			"executing the main loop. Enable this when running replicated kube-batch for high availability")
 	fs.BoolVar(&s.PrintVersion, "version", false, "Show version and quit")
+	fs.BoolVar(&s.ValidateConfig, "validate-config", false, "Validate the configuration file of scheduler-conf and quit")
 	fs.StringVar(&s.LockObjectNamespace, "lock-object-namespace", s.LockObjectNamespace, "Define the namespace of the lock object that is used for leader election")
 	fs.StringVar(&s.ListenAddress, "listen-address", defaultListenAddress, "The address to listen on for HTTP requests.")
 	fs.BoolVar(&s.EnablePriorityClass, "priority-class", true,
 		"Enable PriorityClass to provide the capacity of preemption at pod group level; to disable it, set it false")
 	fs.Float32Var(&s.KubeAPIQPS, "kube-api-qps", defaultQPS, "QPS to use while talking with kubernetes apiserver")
 	fs.IntVar(&s.KubeAPIBurst, "kube-api-burst", defaultBurst, "Burst to use while talking with kubernetes apiserver")
+	fs.StringVar(&s.SessionRecordDir, "session-record-dir", s.SessionRecordDir,
+		"The directory to record scheduling sessions for replay; sessions are not recorded if empty")
+	fs.IntVar(&s.MaxSessionRecords, "max-session-records", defaultMaxSessionRecords, "The max number of session records kept in session-record-dir")
+	fs.BoolVar(&s.EnableExplanations, "enable-explanations", false,
+		"Explain the scheduling decisions of PodGroups in the last session at /debug/explain/")
 }
 
 // CheckOptionOrDie check lock-object-namespace when LeaderElection is enabled
@@ -85,6 +97,9 @@ func (s *ServerOption) CheckOptionOrDie() error {
 	if s.EnableLeaderElection && s.LockObjectNamespace == "" {
 		return fmt.Errorf("lock-object-namespace must not be nil when LeaderElection is enabled")
 	}
+	if s.ValidateConfig && s.SchedulerConf == "" {
+		return fmt.Errorf("scheduler-conf must not be empty when validate-config is enabled")
+	}
 
 	return nil
 }
@@ -43,9 +43,37 @@ func TestAddFlags(t *testing.T) {
 		ListenAddress:  defaultListenAddress,
 		KubeAPIBurst:   defaultBurst,
 		KubeAPIQPS:     defaultQPS,
+
+		MaxSessionRecords: defaultMaxSessionRecords,
 	}
 
 	if !reflect.DeepEqual(expected, s) {
 		t.Errorf("Got different run options than expected.\nGot: %+v\nExpected: %+v\n", s, expected)
 	}
 }
+
+func TestCheckOptionOrDie(t *testing.T) {
+	tests := []struct {
+		name   string
+		option *ServerOption
+		valid  bool
+	}{
+		{
+			name:   "validate config with scheduler conf",
+			option: &ServerOption{ValidateConfig: true, SchedulerConf: "/etc/kube-batch/kube-batch-conf.yaml"},
+			valid:  true,
+		},
+		{
+			name:   "validate config without scheduler conf",
+			option: &ServerOption{ValidateConfig: true},
+			valid:  false,
+		},
+	}
+
+	for _, test := range tests {
+		err := test.option.CheckOptionOrDie()
+		if test.valid != (err == nil) {
+			t.Errorf("case <%s>: expected valid %t, got error %v", test.name, test.valid, err)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package options
+
+import (
+	"fmt"
+
+	"github.com/spf13/pflag"
+)
+
+// ReplayOption is the options of `kube-batch replay`.
+type ReplayOption struct {
+	Record string
+}
+
+// NewReplayOption creates a new ReplayOption.
+func NewReplayOption() *ReplayOption {
+	s := ReplayOption{}
+	return &s
+}
+
+// AddFlags adds flags for replay to the specified FlagSet
+func (s *ReplayOption) AddFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&s.Record, "record", s.Record, "The session record file in session-record-dir to replay")
+}
+
+// CheckOption checks the options of replay
+func (s *ReplayOption) CheckOption() error {
+	if s.Record == "" {
+		return fmt.Errorf("record must not be empty")
+	}
+
+	return nil
+}
@@ -0,0 +1,64 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package options
+
+import (
+	"fmt"
+
+	"github.com/spf13/pflag"
+)
+
+const defaultSimulateCycles = 1
+
+// SimulateOption is the options of `kube-batch simulate`.
+type SimulateOption struct {
+	SchedulerName string
+	SchedulerConf string
+	DefaultQueue  string
+	Traces        []string
+	Cycles        int
+}
+
+// NewSimulateOption creates a new SimulateOption.
+func NewSimulateOption() *SimulateOption {
+	s := SimulateOption{}
+	return &s
+}
+
+// AddFlags adds flags for simulation to the specified FlagSet
+func (s *SimulateOption) AddFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&s.SchedulerName, "scheduler-name", defaultSchedulerName, "kube-batch will handle pods whose .spec.SchedulerName is same as scheduler-name")
+	fs.StringVar(&s.SchedulerConf, "scheduler-conf", "", "The absolute path of scheduler configuration file")
+	fs.StringVar(&s.DefaultQueue, "default-queue", defaultQueue, "The default queue name of the job")
+	fs.StringSliceVar(&s.Traces, "trace", s.Traces, "The YAML files or directories of nodes, queues, PodGroups and pods to simulate")
+	fs.IntVar(&s.Cycles, "cycles", defaultSimulateCycles, "The number of virtual scheduling cycles to simulate")
+}
+
+// CheckOption checks the options of simulation
+func (s *SimulateOption) CheckOption() error {
+	if s.SchedulerConf == "" {
+		return fmt.Errorf("scheduler-conf must not be empty")
+	}
+	if len(s.Traces) == 0 {
+		return fmt.Errorf("trace must not be empty")
+	}
+	if s.Cycles <= 0 {
+		return fmt.Errorf("cycles must be positive, got %d", s.Cycles)
+	}
+
+	return nil
+}
@@ -0,0 +1,53 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"fmt"
+
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/kubernetes-sigs/kube-batch/cmd/kube-batch/app/options"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/replay"
+)
+
+// RunReplay replays the recorded session, prints the replayed decisions in YAML,
+// and returns error if they are different from the recorded ones.
+func RunReplay(opt *options.ReplayOption) error {
+	record, err := replay.LoadSessionRecord(opt.Record)
+	if err != nil {
+		return err
+	}
+
+	decisions, err := replay.Replay(record)
+	if err != nil {
+		return err
+	}
+
+	out, err := yaml.Marshal(decisions)
+	if err != nil {
+		return err
+	}
+	fmt.Print(string(out))
+
+	if !decisions.Equal(&record.Decisions) {
+		recorded, _ := yaml.Marshal(record.Decisions)
+		return fmt.Errorf("the replayed decisions are different from the recorded ones:\n%s", recorded)
+	}
+
+	return nil
+}
@@ -19,6 +19,7 @@ package app
 import (
 	"context"
 	"fmt"
+	"io/ioutil"
 	"net/http"
 	"os"
 	"time"
@@ -26,6 +27,9 @@ import (
 	"github.com/golang/glog"
 	"github.com/kubernetes-sigs/kube-batch/cmd/kube-batch/app/options"
 	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/debug"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/framework"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/replay"
 	"github.com/kubernetes-sigs/kube-batch/pkg/version"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 
@@ -72,12 +76,31 @@ func buildConfig(opt *options.ServerOption) (*rest.Config, error) {
 	return cfg, nil
 }
 
+// validateSchedulerConf validates the configuration file of scheduler, which is
+// used to check the configuration in CI before deploying it.
+func validateSchedulerConf(confPath string) error {
+	data, err := ioutil.ReadFile(confPath)
+	if err != nil {
+		return err
+	}
+	if err := scheduler.ValidateSchedulerConf(string(data)); err != nil {
+		return fmt.Errorf("invalid scheduler configuration <%s>: %v", confPath, err)
+	}
+
+	fmt.Printf("Scheduler configuration <%s> is valid.\n", confPath)
+	return nil
+}
+
 // Run the kubeBatch scheduler
 func Run(opt *options.ServerOption) error {
 	if opt.PrintVersion {
 		version.PrintVersionAndExit(apiVersion)
 	}
 
+	if opt.ValidateConfig {
+		return validateSchedulerConf(opt.SchedulerConf)
+	}
+
 	config, err := buildConfig(opt)
 	if err != nil {
 		return err
@@ -90,11 +113,27 @@ func Run(opt *options.ServerOption) error {
 		opt.SchedulePeriod,
 		opt.DefaultQueue)
 	if err != nil {
-		panic(err)
+		return err
 	}
 
+	if len(opt.SessionRecordDir) != 0 {
+		recorder, err := replay.NewRecorder(opt.SessionRecordDir, opt.MaxSessionRecords)
+		if err != nil {
+			return err
+		}
+		sched.SetSessionRecorder(recorder)
+	}
+
+	framework.EnableExplanations(opt.EnableExplanations)
+
 	go func() {
 		http.Handle("/metrics", promhttp.Handler())
+		if opt.EnableExplanations {
+			http.Handle(debug.ExplainPath, debug.ExplainHandler())
+		}
+		if dumper, ok := sched.Cache().(debug.CacheDumper); ok {
+			http.Handle(debug.CachePath, debug.CacheHandler(dumper))
+		}
 		glog.Fatalf("Prometheus Http Server failed %s", http.ListenAndServe(opt.ListenAddress, nil))
 	}()
 
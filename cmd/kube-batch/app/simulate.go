@@ -0,0 +1,54 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/kubernetes-sigs/kube-batch/cmd/kube-batch/app/options"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/simulator"
+)
+
+// RunSimulate simulates the scheduling of cluster trace offline, and prints the
+// placements, evictions and PodGroup phases of each cycle in YAML.
+func RunSimulate(opt *options.SimulateOption) error {
+	schedulerConf, err := ioutil.ReadFile(opt.SchedulerConf)
+	if err != nil {
+		return err
+	}
+
+	trace, err := simulator.LoadTrace(opt.Traces...)
+	if err != nil {
+		return err
+	}
+
+	sim, err := simulator.New(string(schedulerConf), opt.SchedulerName, opt.DefaultQueue, trace)
+	if err != nil {
+		return err
+	}
+
+	out, err := yaml.Marshal(sim.Run(opt.Cycles))
+	if err != nil {
+		return err
+	}
+	fmt.Print(string(out))
+
+	return nil
+}
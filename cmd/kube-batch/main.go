@@ -16,6 +16,7 @@ limitations under the License.
 package main
 
 import (
+	goflag "flag"
 	"fmt"
 	"os"
 	"runtime"
@@ -46,6 +47,17 @@ var logFlushFreq = pflag.Duration("log-flush-frequency", 5*time.Second, "Maximum
 func main() {
 	runtime.GOMAXPROCS(runtime.NumCPU())
 
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "simulate":
+			simulate(os.Args[2:])
+			return
+		case "replay":
+			replaySession(os.Args[2:])
+			return
+		}
+	}
+
 	s := options.NewServerOption()
 	s.AddFlags(pflag.CommandLine)
 	s.RegisterOptions()
@@ -65,3 +77,45 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+// simulate runs `kube-batch simulate`, which simulates scheduling offline.
+func simulate(args []string) {
+	s := options.NewSimulateOption()
+	fs := pflag.NewFlagSet("simulate", pflag.ExitOnError)
+	s.AddFlags(fs)
+	fs.AddGoFlagSet(goflag.CommandLine)
+	fs.Parse(args)
+
+	if err := s.CheckOption(); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+
+	err := app.RunSimulate(s)
+	glog.Flush()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+}
+
+// replaySession runs `kube-batch replay`, which replays a recorded session offline.
+func replaySession(args []string) {
+	s := options.NewReplayOption()
+	fs := pflag.NewFlagSet("replay", pflag.ExitOnError)
+	s.AddFlags(fs)
+	fs.AddGoFlagSet(goflag.CommandLine)
+	fs.Parse(args)
+
+	if err := s.CheckOption(); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+
+	err := app.RunReplay(s)
+	glog.Flush()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+}
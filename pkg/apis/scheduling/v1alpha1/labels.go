@@ -19,3 +19,7 @@ package v1alpha1
 // GroupNameAnnotationKey is the annotation key of Pod to identify
 // which PodGroup it belongs to.
 const GroupNameAnnotationKey = "scheduling.k8s.io/group-name"
+
+// ExpectedRuntimeAnnotationKey is the annotation key of PodGroup to declare the
+// expected runtime of its tasks, e.g. "30m", which is used by backfill.
+const ExpectedRuntimeAnnotationKey = "scheduling.k8s.io/expected-runtime"
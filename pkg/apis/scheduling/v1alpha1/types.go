@@ -46,6 +46,10 @@ type PodGroupConditionType string
 
 const (
 	PodGroupUnschedulableType PodGroupConditionType = "Unschedulable"
+
+	// PodGroupPreemptedType is set on the PodGroup before its pods are evicted by
+	// the scheduler, so the controllers can save the state of the job.
+	PodGroupPreemptedType PodGroupConditionType = "Preempted"
 )
 
 // PodGroupCondition contains details for the current state of this pod group.
@@ -84,6 +88,9 @@ const (
 
 	// NotEnoughPodsReason is probed if there're not enough tasks compared to `spec.minMember`
 	NotEnoughPodsReason string = "NotEnoughTasks"
+
+	// PreemptedReason is probed if pods of PodGroup are evicted for other pods
+	PreemptedReason string = "Preempted"
 )
 
 // +genclient
@@ -132,6 +139,12 @@ type PodGroupSpec struct {
 	// if there's not enough resources to start all tasks, the scheduler
 	// will not start anyone.
 	MinResources *v1.ResourceList `json:"minResources,omitempty" protobuf:"bytes,4,opt,name=minResources"`
+
+	// EvictionGracePeriodSeconds is the grace period of the pods evicted by the
+	// scheduler, e.g. to checkpoint; it overrides the one of queue. If not specified,
+	// the terminationGracePeriodSeconds of pod is used.
+	// +optional
+	EvictionGracePeriodSeconds *int64 `json:"evictionGracePeriodSeconds,omitempty" protobuf:"varint,5,opt,name=evictionGracePeriodSeconds"`
 }
 
 // PodGroupStatus represents the current state of a pod group.
@@ -192,6 +205,19 @@ type Queue struct {
 	Status QueueStatus `json:"status,omitempty" protobuf:"bytes,3,opt,name=status"`
 }
 
+// QueueState is the state of queue.
+type QueueState string
+
+const (
+	// QueueStateOpen means the queue accepts new PodGroups.
+	QueueStateOpen QueueState = "Open"
+	// QueueStateClosed means the queue rejects new PodGroups.
+	QueueStateClosed QueueState = "Closed"
+	// QueueStateClosing means the queue is closed, but there're still
+	// PodGroups running in it; it's only used in the status of queue.
+	QueueStateClosing QueueState = "Closing"
+)
+
 // QueueStatus represents the status of Queue.
 type QueueStatus struct {
 	// The number of 'Unknonw' PodGroup in this queue.
@@ -200,12 +226,52 @@ type QueueStatus struct {
 	Pending int32 `json:"pending,omitempty" protobuf:"bytes,2,opt,name=pending"`
 	// The number of 'Running' PodGroup in this queue.
 	Running int32 `json:"running,omitempty" protobuf:"bytes,3,opt,name=running"`
+
+	// State is the current state of queue: Open, Closing or Closed.
+	// +optional
+	State QueueState `json:"state,omitempty" protobuf:"bytes,4,opt,name=state"`
+
+	// The number of 'Inqueue' PodGroup in this queue.
+	Inqueue int32 `json:"inqueue,omitempty" protobuf:"bytes,5,opt,name=inqueue"`
+
+	// Allocated is the resources allocated to the PodGroups in this queue.
+	// +optional
+	Allocated v1.ResourceList `json:"allocated,omitempty" protobuf:"bytes,6,opt,name=allocated"`
+	// Deserved is the resources this queue deserves, calculated by scheduler
+	// according to the weight, e.g. proportion plugin.
+	// +optional
+	Deserved v1.ResourceList `json:"deserved,omitempty" protobuf:"bytes,7,opt,name=deserved"`
+	// Share is the dominant share of allocated resources in deserved resources.
+	// +optional
+	Share string `json:"share,omitempty" protobuf:"bytes,8,opt,name=share"`
 }
 
 // QueueSpec represents the template of Queue.
 type QueueSpec struct {
 	Weight     int32           `json:"weight,omitempty" protobuf:"bytes,1,opt,name=weight"`
 	Capability v1.ResourceList `json:"capability,omitempty" protobuf:"bytes,2,opt,name=capability"`
+
+	// Parent is the name of the parent queue; the queue shares the deserved resources
+	// of its parent with its siblings by weight. If not specified or the parent does
+	// not exist, it's a top-level queue.
+	// +optional
+	Parent string `json:"parent,omitempty" protobuf:"bytes,3,opt,name=parent"`
+
+	// Guarantee is the resources guaranteed to the queue; they're reserved for the queue
+	// before the rest resources are divided by weight, and will not be reclaimed by others.
+	// +optional
+	Guarantee v1.ResourceList `json:"guarantee,omitempty" protobuf:"bytes,4,opt,name=guarantee"`
+
+	// State is the desired state of queue: Open or Closed, default to Open.
+	// The PodGroups in a Closed queue will not be enqueued, but the running
+	// PodGroups will be kept until finished.
+	// +optional
+	State QueueState `json:"state,omitempty" protobuf:"bytes,5,opt,name=state"`
+
+	// EvictionGracePeriodSeconds is the grace period of the pods in the queue evicted
+	// by the scheduler, unless specified by their PodGroup.
+	// +optional
+	EvictionGracePeriodSeconds *int64 `json:"evictionGracePeriodSeconds,omitempty" protobuf:"varint,6,opt,name=evictionGracePeriodSeconds"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
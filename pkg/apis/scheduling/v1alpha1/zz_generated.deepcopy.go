@@ -118,6 +118,11 @@ func (in *PodGroupSpec) DeepCopyInto(out *PodGroupSpec) {
 			}
 		}
 	}
+	if in.EvictionGracePeriodSeconds != nil {
+		in, out := &in.EvictionGracePeriodSeconds, &out.EvictionGracePeriodSeconds
+		*out = new(int64)
+		**out = **in
+	}
 	return
 }
 
@@ -160,7 +165,7 @@ func (in *Queue) DeepCopyInto(out *Queue) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 	return
 }
 
@@ -225,6 +230,18 @@ func (in *QueueSpec) DeepCopyInto(out *QueueSpec) {
 			(*out)[key] = val.DeepCopy()
 		}
 	}
+	if in.Guarantee != nil {
+		in, out := &in.Guarantee, &out.Guarantee
+		*out = make(v1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+	if in.EvictionGracePeriodSeconds != nil {
+		in, out := &in.EvictionGracePeriodSeconds, &out.EvictionGracePeriodSeconds
+		*out = new(int64)
+		**out = **in
+	}
 	return
 }
 
@@ -241,6 +258,20 @@ func (in *QueueSpec) DeepCopy() *QueueSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *QueueStatus) DeepCopyInto(out *QueueStatus) {
 	*out = *in
+	if in.Allocated != nil {
+		in, out := &in.Allocated, &out.Allocated
+		*out = make(v1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+	if in.Deserved != nil {
+		in, out := &in.Deserved, &out.Deserved
+		*out = make(v1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
 	return
 }
 
@@ -30,6 +30,7 @@ type createFlags struct {
 
 	Name   string
 	Weight int32
+	Parent string
 }
 
 var createQueueFlags = &createFlags{}
@@ -40,6 +41,7 @@ func InitRunFlags(cmd *cobra.Command) {
 
 	cmd.Flags().StringVarP(&createQueueFlags.Name, "name", "n", "test", "the name of queue")
 	cmd.Flags().Int32VarP(&createQueueFlags.Weight, "weight", "w", 1, "the weight of the queue")
+	cmd.Flags().StringVarP(&createQueueFlags.Parent, "parent", "p", "", "the parent of the queue")
 
 }
 
@@ -56,6 +58,7 @@ func CreateQueue() error {
 		},
 		Spec: vkapi.QueueSpec{
 			Weight: int32(createQueueFlags.Weight),
+			Parent: createQueueFlags.Parent,
 		},
 	}
 
@@ -38,6 +38,9 @@ const (
 
 	// Name of queue
 	Name string = "Name"
+
+	// State of queue
+	State string = "State"
 )
 
 var listQueueFlags = &listFlags{}
@@ -71,14 +74,14 @@ func ListQueue() error {
 
 // PrintQueues prints queue information
 func PrintQueues(queues *v1alpha1.QueueList, writer io.Writer) {
-	_, err := fmt.Fprintf(writer, "%-25s%-8s\n",
-		Name, Weight)
+	_, err := fmt.Fprintf(writer, "%-25s%-8s%-8s\n",
+		Name, Weight, State)
 	if err != nil {
 		fmt.Printf("Failed to print queue command result: %s.\n", err)
 	}
 	for _, queue := range queues.Items {
-		_, err = fmt.Fprintf(writer, "%-25s%-8d\n",
-			queue.Name, queue.Spec.Weight)
+		_, err = fmt.Fprintf(writer, "%-25s%-8d%-8s\n",
+			queue.Name, queue.Spec.Weight, queue.Status.State)
 		if err != nil {
 			fmt.Printf("Failed to print queue command result: %s.\n", err)
 		}
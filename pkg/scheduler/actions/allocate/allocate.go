@@ -25,6 +25,10 @@ import (
 	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/util"
 )
 
+func init() {
+	framework.RegisterActionArguments("allocate")
+}
+
 type allocateAction struct {
 	ssn *framework.Session
 }
@@ -37,7 +41,7 @@ func (alloc *allocateAction) Name() string {
 	return "allocate"
 }
 
-func (alloc *allocateAction) Initialize() {}
+func (alloc *allocateAction) Initialize(framework.Arguments) {}
 
 func (alloc *allocateAction) Execute(ssn *framework.Session) {
 	glog.V(3).Infof("Enter Allocate ...")
@@ -78,6 +82,12 @@ func (alloc *allocateAction) Execute(ssn *framework.Session) {
 	allNodes := util.GetNodeList(ssn.Nodes)
 
 	predicateFn := func(task *api.TaskInfo, node *api.NodeInfo) error {
+		// The nodes locked for the starving job are only for the job, and the tasks
+		// which finish before the lock expires.
+		if err := ssn.CheckReservation(task, node); err != nil {
+			return err
+		}
+
 		// Check for Resource Predicate
 		// TODO: We could not allocate resource to task from both node.Idle and node.Releasing now,
 		// after it is done, we could change the following compare to:
@@ -132,6 +142,8 @@ func (alloc *allocateAction) Execute(ssn *framework.Session) {
 		glog.V(3).Infof("Try to allocate resource to %d tasks of Job <%v/%v>",
 			tasks.Len(), job.Namespace, job.Name)
 
+		stmt := ssn.Statement()
+
 		for !tasks.Empty() {
 			task := tasks.Pop().(*api.TaskInfo)
 
@@ -153,13 +165,14 @@ func (alloc *allocateAction) Execute(ssn *framework.Session) {
 			}
 
 			nodeScores := util.PrioritizeNodes(task, predicateNodes, ssn.BatchNodeOrderFn, ssn.NodeOrderMapFn, ssn.NodeOrderReduceFn)
+			ssn.RecordNodeScores(task, nodeScores)
 
 			node := util.SelectBestNode(nodeScores)
 			// Allocate idle resource to the task.
 			if task.InitResreq.LessEqual(node.Idle) {
 				glog.V(3).Infof("Binding Task <%v/%v> to node <%v>",
 					task.Namespace, task.Name, node.Name)
-				if err := ssn.Allocate(task, node.Name); err != nil {
+				if err := stmt.Allocate(task, node.Name); err != nil {
 					glog.Errorf("Failed to bind Task %v on %v in Session %v, err: %v",
 						task.UID, node.Name, ssn.UID, err)
 				}
@@ -174,7 +187,7 @@ func (alloc *allocateAction) Execute(ssn *framework.Session) {
 				if task.InitResreq.LessEqual(node.Releasing) {
 					glog.V(3).Infof("Pipelining Task <%v/%v> to node <%v> for <%v> on <%v>",
 						task.Namespace, task.Name, node.Name, task.InitResreq, node.Releasing)
-					if err := ssn.Pipeline(task, node.Name); err != nil {
+					if err := stmt.Pipeline(task, node.Name); err != nil {
 						glog.Errorf("Failed to pipeline Task %v on %v in Session %v",
 							task.UID, node.Name, ssn.UID)
 					}
@@ -187,6 +200,17 @@ func (alloc *allocateAction) Execute(ssn *framework.Session) {
 			}
 		}
 
+		// Commit the allocations only if the job is ready; the pipelined job keeps
+		// its operations in session to hold the releasing resources. Otherwise
+		// release the resources held by its tasks so that other jobs could use them.
+		if ssn.JobReady(job) {
+			stmt.Commit()
+		} else if !ssn.JobPipelined(job) {
+			glog.V(3).Infof("Job <%v/%v> is not ready, discard its allocations.",
+				job.Namespace, job.Name)
+			stmt.Discard()
+		}
+
 		// Added Queue back until no job in Queue.
 		queues.Push(queue)
 	}
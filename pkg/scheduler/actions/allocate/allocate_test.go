@@ -31,12 +31,14 @@ import (
 	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/conf"
 	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/framework"
 	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/plugins/drf"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/plugins/gang"
 	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/plugins/proportion"
 	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/util"
 )
 
 func TestAllocate(t *testing.T) {
 	framework.RegisterPluginBuilder("drf", drf.New)
+	framework.RegisterPluginBuilder("gang", gang.New)
 	framework.RegisterPluginBuilder("proportion", proportion.New)
 	defer framework.CleanupPluginBuilders()
 
@@ -142,6 +144,57 @@ func TestAllocate(t *testing.T) {
 				"c1/p1": "n1",
 			},
 		},
+		{
+			name: "release resources of the gang job which can not be ready",
+			podGroups: []*kbv1.PodGroup{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "pg1",
+						Namespace: "c1",
+					},
+					Spec: kbv1.PodGroupSpec{
+						Queue:     "c1",
+						MinMember: 3,
+					},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "pg2",
+						Namespace: "c1",
+					},
+					Spec: kbv1.PodGroupSpec{
+						Queue:     "c1",
+						MinMember: 2,
+					},
+				},
+			},
+			pods: []*v1.Pod{
+				// pending pods of pg1 which requests 3 cpus in total
+				util.BuildPod("c1", "p1", "", v1.PodPending, util.BuildResourceList("1", "1G"), "pg1", make(map[string]string), make(map[string]string)),
+				util.BuildPod("c1", "p2", "", v1.PodPending, util.BuildResourceList("1", "1G"), "pg1", make(map[string]string), make(map[string]string)),
+				util.BuildPod("c1", "p3", "", v1.PodPending, util.BuildResourceList("1", "1G"), "pg1", make(map[string]string), make(map[string]string)),
+				// pending pods of pg2 which requests 2 cpus in total
+				util.BuildPod("c1", "p4", "", v1.PodPending, util.BuildResourceList("1", "1G"), "pg2", make(map[string]string), make(map[string]string)),
+				util.BuildPod("c1", "p5", "", v1.PodPending, util.BuildResourceList("1", "1G"), "pg2", make(map[string]string), make(map[string]string)),
+			},
+			nodes: []*v1.Node{
+				util.BuildNode("n1", util.BuildResourceList("2", "4Gi"), make(map[string]string)),
+			},
+			queues: []*kbv1.Queue{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "c1",
+					},
+					Spec: kbv1.QueueSpec{
+						Weight: 1,
+					},
+				},
+			},
+			expected: map[string]string{
+				"c1/p4": "n1",
+				"c1/p5": "n1",
+			},
+		},
 	}
 
 	allocate := New()
@@ -185,6 +238,11 @@ func TestAllocate(t *testing.T) {
 						EnabledPreemptable: &trueValue,
 						EnabledJobOrder:    &trueValue,
 					},
+					{
+						Name:                "gang",
+						EnabledJobReady:     &trueValue,
+						EnabledJobPipelined: &trueValue,
+					},
 					{
 						Name:               "proportion",
 						EnabledQueueOrder:  &trueValue,
@@ -210,3 +268,69 @@ func TestAllocate(t *testing.T) {
 		}
 	}
 }
+
+func TestAllocatePipelined(t *testing.T) {
+	framework.RegisterPluginBuilder("gang", gang.New)
+	defer framework.CleanupPluginBuilders()
+
+	binder := &util.FakeBinder{
+		Binds:   map[string]string{},
+		Channel: make(chan string, 1),
+	}
+	schedulerCache := &cache.SchedulerCache{
+		Nodes:         make(map[string]*api.NodeInfo),
+		Jobs:          make(map[api.JobID]*api.JobInfo),
+		Queues:        make(map[api.QueueID]*api.QueueInfo),
+		Binder:        binder,
+		StatusUpdater: &util.FakeStatusUpdater{},
+		VolumeBinder:  &util.FakeVolumeBinder{},
+
+		Recorder: record.NewFakeRecorder(100),
+	}
+	schedulerCache.AddNode(util.BuildNode("n1", util.BuildResourceList("2", "4Gi"), make(map[string]string)))
+	schedulerCache.AddQueue(&kbv1.Queue{
+		ObjectMeta: metav1.ObjectMeta{Name: "c1"},
+		Spec:       kbv1.QueueSpec{Weight: 1},
+	})
+	for _, pg := range []string{"pg1", "pg2"} {
+		schedulerCache.AddPodGroup(&kbv1.PodGroup{
+			ObjectMeta: metav1.ObjectMeta{Name: pg, Namespace: "c1"},
+			Spec:       kbv1.PodGroupSpec{MinMember: 1, Queue: "c1"},
+		})
+	}
+	// The node is occupied by a releasing pod, so the pending pod can only be
+	// pipelined onto it.
+	releasing := util.BuildPod("c1", "p1", "n1", v1.PodRunning, util.BuildResourceList("2", "4G"), "pg1", make(map[string]string), make(map[string]string))
+	now := metav1.Now()
+	releasing.DeletionTimestamp = &now
+	schedulerCache.AddPod(releasing)
+	schedulerCache.AddPod(util.BuildPod("c1", "p2", "", v1.PodPending, util.BuildResourceList("1", "1G"), "pg2", make(map[string]string), make(map[string]string)))
+
+	trueValue := true
+	ssn := framework.OpenSession(schedulerCache, []conf.Tier{
+		{
+			Plugins: []conf.PluginOption{
+				{
+					Name:                "gang",
+					EnabledJobReady:     &trueValue,
+					EnabledJobPipelined: &trueValue,
+				},
+			},
+		},
+	})
+	defer framework.CloseSession(ssn)
+
+	New().Execute(ssn)
+
+	job := ssn.Jobs[api.JobID("c1/pg2")]
+	if job == nil {
+		t.Fatalf("failed to find job c1/pg2")
+	}
+	task := job.Tasks[api.TaskID("c1-p2")]
+	if task.Status != api.Pipelined || task.NodeName != "n1" {
+		t.Errorf("expected task c1/p2 pipelined to n1, got %v on <%s>", task.Status, task.NodeName)
+	}
+	if len(binder.Binds) != 0 {
+		t.Errorf("expected no binding of pipelined task, got %v", binder.Binds)
+	}
+}
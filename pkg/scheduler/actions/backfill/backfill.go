@@ -17,13 +17,20 @@ limitations under the License.
 package backfill
 
 import (
+	"sort"
+
 	"github.com/golang/glog"
 
 	"github.com/kubernetes-sigs/kube-batch/pkg/apis/scheduling/v1alpha1"
 	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/api"
 	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/framework"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/util"
 )
 
+func init() {
+	framework.RegisterActionArguments("backfill")
+}
+
 type backfillAction struct {
 	ssn *framework.Session
 }
@@ -36,13 +43,24 @@ func (alloc *backfillAction) Name() string {
 	return "backfill"
 }
 
-func (alloc *backfillAction) Initialize() {}
+func (alloc *backfillAction) Initialize(framework.Arguments) {}
 
 func (alloc *backfillAction) Execute(ssn *framework.Session) {
 	glog.V(3).Infof("Enter Backfill ...")
 	defer glog.V(3).Infof("Leaving Backfill ...")
 
-	// TODO (k82cn): When backfill, it's also need to balance between Queues.
+	// Without nodes locked by reserve action, the nodes on which the blocked job of
+	// the highest order is expected to start are reserved until it starts, so the
+	// tasks of other jobs are backfilled only if they finish by then.
+	if ssn.Reservation == nil {
+		ssn.Reservation = ssn.ReserveBlockedJob()
+	}
+
+	// The queues take turns to backfill one job of the highest order at a time, in
+	// the order of QueueOrderFn.
+	queues := util.NewPriorityQueue(ssn.QueueOrderFn)
+	jobsMap := map[api.QueueID]*util.PriorityQueue{}
+
 	for _, job := range ssn.Jobs {
 		if job.PodGroup.Status.Phase == v1alpha1.PodGroupPending {
 			continue
@@ -52,41 +70,140 @@ func (alloc *backfillAction) Execute(ssn *framework.Session) {
 			continue
 		}
 
+		queue, found := ssn.Queues[job.Queue]
+		if !found {
+			glog.Warningf("Skip backfilling Job <%s/%s> because its queue %s is not found",
+				job.Namespace, job.Name, job.Queue)
+			continue
+		}
+
+		if _, found := jobsMap[job.Queue]; !found {
+			jobsMap[job.Queue] = util.NewPriorityQueue(ssn.JobOrderFn)
+			queues.Push(queue)
+		}
+		jobsMap[job.Queue].Push(job)
+	}
+
+	allNodes := util.GetNodeList(ssn.Nodes)
+
+	for !queues.Empty() {
+		queue := queues.Pop().(*api.QueueInfo)
+		jobs := jobsMap[queue.UID]
+		if jobs.Empty() {
+			continue
+		}
+		job := jobs.Pop().(*api.JobInfo)
+
+		var tasks []*api.TaskInfo
 		for _, task := range job.TaskStatusIndex[api.Pending] {
 			if task.InitResreq.IsEmpty() {
-				allocated := false
-				fe := api.NewFitErrors()
-
-				// As task did not request resources, so it only need to meet predicates.
-				// TODO (k82cn): need to prioritize nodes to avoid pod hole.
-				for _, node := range ssn.Nodes {
-					// TODO (k82cn): predicates did not consider pod number for now, there'll
-					// be ping-pong case here.
-					if err := ssn.PredicateFn(task, node); err != nil {
-						glog.V(3).Infof("Predicates failed for task <%s/%s> on node <%s>: %v",
-							task.Namespace, task.Name, node.Name, err)
-						fe.SetNodeError(node.Name, err)
-						continue
-					}
-
-					glog.V(3).Infof("Binding Task <%v/%v> to node <%v>", task.Namespace, task.Name, node.Name)
-					if err := ssn.Allocate(task, node.Name); err != nil {
-						glog.Errorf("Failed to bind Task %v on %v in Session %v", task.UID, node.Name, ssn.UID)
-						fe.SetNodeError(node.Name, err)
-						continue
-					}
-
-					allocated = true
-					break
-				}
-
-				if !allocated {
-					job.NodesFitErrors[task.UID] = fe
-				}
-			} else {
-				// TODO (k82cn): backfill for other case.
+				backfillBestEffort(ssn, job, task, allNodes)
+			} else if _, found := ssn.TaskRuntime(task); found {
+				// Only the tasks whose runtime is known are backfilled, so that they
+				// are known to finish before the reserved job starts.
+				tasks = append(tasks, task)
 			}
 		}
+
+		if len(tasks) != 0 {
+			backfillJob(ssn, job, tasks, allNodes)
+		}
+
+		queues.Push(queue)
+	}
+}
+
+// hasPodSlot returns an error if the node can not hold any more tasks.
+func hasPodSlot(task *api.TaskInfo, node *api.NodeInfo) error {
+	if len(node.Tasks) >= node.Allocatable.MaxTaskNum {
+		return api.NewFitError(task, node, api.NodePodNumberExceeded)
+	}
+	return nil
+}
+
+// selectNode returns the node of the highest score by the node order functions among
+// the nodes which pass predicate, or nil if no node passes.
+func selectNode(ssn *framework.Session, task *api.TaskInfo, nodes []*api.NodeInfo,
+	predicate api.PredicateFn) (*api.NodeInfo, *api.FitErrors) {
+	predicateNodes, fitErrors := util.PredicateNodes(task, nodes, predicate)
+	if len(predicateNodes) == 0 {
+		return nil, fitErrors
+	}
+
+	nodeScores := util.PrioritizeNodes(task, predicateNodes, ssn.BatchNodeOrderFn, ssn.NodeOrderMapFn, ssn.NodeOrderReduceFn)
+	ssn.RecordNodeScores(task, nodeScores)
+
+	return util.SelectBestNode(nodeScores), nil
+}
+
+// backfillBestEffort binds the task which does not request any resources to a node;
+// as it does not request resources, it only needs a pod slot and to meet predicates,
+// and it's placed onto the reserved nodes too, as it takes nothing from the reserved job.
+func backfillBestEffort(ssn *framework.Session, job *api.JobInfo, task *api.TaskInfo, nodes []*api.NodeInfo) {
+	node, fe := selectNode(ssn, task, nodes, func(task *api.TaskInfo, node *api.NodeInfo) error {
+		if err := hasPodSlot(task, node); err != nil {
+			return err
+		}
+		return ssn.PredicateFn(task, node)
+	})
+	if node == nil {
+		job.NodesFitErrors[task.UID] = fe
+		return
+	}
+
+	glog.V(3).Infof("Binding Task <%v/%v> to node <%v>", task.Namespace, task.Name, node.Name)
+	if err := ssn.Allocate(task, node.Name); err != nil {
+		glog.Errorf("Failed to bind Task %v on %v in Session %v", task.UID, node.Name, ssn.UID)
+		fe = api.NewFitErrors()
+		fe.SetNodeError(node.Name, err)
+		job.NodesFitErrors[task.UID] = fe
+	}
+}
+
+// backfillJob places the tasks of job into the idle resources of nodes; the tasks are
+// placed only if the job is ready with them, otherwise they are discarded.
+func backfillJob(ssn *framework.Session, job *api.JobInfo, tasks []*api.TaskInfo, nodes []*api.NodeInfo) {
+	if queue, found := ssn.Queues[job.Queue]; found && ssn.Overused(queue) {
+		glog.V(3).Infof("Queue <%s> is overused, skip backfilling Job <%s/%s>.",
+			job.Queue, job.Namespace, job.Name)
+		return
+	}
+
+	sort.Slice(tasks, func(i, j int) bool {
+		return ssn.TaskOrderFn(tasks[i], tasks[j])
+	})
+
+	predicate := func(task *api.TaskInfo, node *api.NodeInfo) error {
+		if !task.InitResreq.LessEqual(node.Idle) {
+			return api.NewFitError(task, node, api.NodeResourceFitFailed)
+		}
+		if err := hasPodSlot(task, node); err != nil {
+			return err
+		}
+		if err := ssn.CheckReservation(task, node); err != nil {
+			return err
+		}
+		return ssn.PredicateFn(task, node)
+	}
+
+	stmt := ssn.Statement()
+	for _, task := range tasks {
+		node, fe := selectNode(ssn, task, nodes, predicate)
+		if node == nil {
+			job.NodesFitErrors[task.UID] = fe
+			continue
+		}
+
+		glog.V(3).Infof("Backfilling Task <%v/%v> to node <%v>", task.Namespace, task.Name, node.Name)
+		if err := stmt.Allocate(task, node.Name); err != nil {
+			glog.Errorf("Failed to backfill Task %v on %v in Session %v", task.UID, node.Name, ssn.UID)
+		}
+	}
+
+	if ssn.JobReady(job) {
+		stmt.Commit()
+	} else {
+		stmt.Discard()
 	}
 }
 
@@ -0,0 +1,338 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backfill
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	kbv1 "github.com/kubernetes-sigs/kube-batch/pkg/apis/scheduling/v1alpha1"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/api"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/cache"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/conf"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/framework"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/plugins/gang"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/util"
+)
+
+// orderPlugin scores the nodes by the given scores, and orders the given queue first.
+type orderPlugin struct {
+	nodeScores map[string]float64
+	firstQueue api.QueueID
+}
+
+func (op *orderPlugin) Name() string {
+	return "order"
+}
+
+func (op *orderPlugin) OnSessionOpen(ssn *framework.Session) {
+	ssn.AddNodeOrderFn(op.Name(), func(task *api.TaskInfo, node *api.NodeInfo) (float64, error) {
+		return op.nodeScores[node.Name], nil
+	})
+	ssn.AddQueueOrderFn(op.Name(), func(l, r interface{}) int {
+		if l.(*api.QueueInfo).UID == op.firstQueue {
+			return -1
+		}
+		if r.(*api.QueueInfo).UID == op.firstQueue {
+			return 1
+		}
+		return 0
+	})
+}
+
+func (op *orderPlugin) OnSessionClose(ssn *framework.Session) {}
+
+func buildBestEffortCache(nodes []*v1.Node, pods []*v1.Pod) *cache.SchedulerCache {
+	schedulerCache := cache.NewOfflineCache("kube-batch", "q1")
+	schedulerCache.Evictor = &util.FakeEvictor{}
+	schedulerCache.StatusUpdater = &util.FakeStatusUpdater{}
+	schedulerCache.VolumeBinder = &util.FakeVolumeBinder{}
+	schedulerCache.Binder = &util.FakeBinder{
+		Binds:   map[string]string{},
+		Channel: make(chan string, 4),
+	}
+	schedulerCache.Recorder = record.NewFakeRecorder(100)
+
+	for _, node := range nodes {
+		schedulerCache.AddNode(node)
+	}
+	for _, pod := range pods {
+		schedulerCache.AddPod(pod)
+	}
+
+	return schedulerCache
+}
+
+func TestBackfillBestEffort(t *testing.T) {
+	defer framework.CleanupPluginBuilders()
+
+	buildNode := func(name, pods string) *v1.Node {
+		alloc := util.BuildResourceList("2", "2G")
+		alloc[v1.ResourcePods] = resource.MustParse(pods)
+		return util.BuildNode(name, alloc, make(map[string]string))
+	}
+	buildPod := func(name, node, pg string) *v1.Pod {
+		phase := v1.PodPending
+		if len(node) != 0 {
+			phase = v1.PodRunning
+		}
+		return util.BuildPod("c1", name, node, phase, v1.ResourceList{}, pg,
+			make(map[string]string), make(map[string]string))
+	}
+
+	tests := []struct {
+		name      string
+		plugin    *orderPlugin
+		nodes     []*v1.Node
+		queues    []string
+		podGroups map[string]string
+		pods      []*v1.Pod
+		expected  map[string]string
+	}{
+		{
+			name:      "task is bound to node of highest score",
+			plugin:    &orderPlugin{nodeScores: map[string]float64{"n2": 10, "n3": 50}},
+			nodes:     []*v1.Node{buildNode("n1", "10"), buildNode("n2", "10"), buildNode("n3", "10")},
+			queues:    []string{"q1"},
+			podGroups: map[string]string{"pg1": "q1"},
+			pods:      []*v1.Pod{buildPod("p1", "", "pg1")},
+			expected:  map[string]string{"c1-p1": "n3"},
+		},
+		{
+			name:      "node without pod slot is skipped",
+			plugin:    &orderPlugin{nodeScores: map[string]float64{"n1": 100, "n3": 50}},
+			nodes:     []*v1.Node{buildNode("n1", "1"), buildNode("n2", "10"), buildNode("n3", "10")},
+			queues:    []string{"q1"},
+			podGroups: map[string]string{"pg1": "q1", "pg2": "q1"},
+			pods:      []*v1.Pod{buildPod("p1", "", "pg1"), buildPod("p2", "n1", "pg2")},
+			expected:  map[string]string{"c1-p1": "n3"},
+		},
+		{
+			name:      "queue of higher order is backfilled first",
+			plugin:    &orderPlugin{firstQueue: "q2"},
+			nodes:     []*v1.Node{buildNode("n1", "1")},
+			queues:    []string{"q1", "q2"},
+			podGroups: map[string]string{"pg1": "q1", "pg2": "q2"},
+			pods:      []*v1.Pod{buildPod("p1", "", "pg1"), buildPod("p2", "", "pg2")},
+			expected:  map[string]string{"c1-p1": "", "c1-p2": "n1"},
+		},
+	}
+
+	for i, test := range tests {
+		plugin := test.plugin
+		framework.RegisterPluginBuilder("order", func(framework.Arguments) framework.Plugin {
+			return plugin
+		})
+
+		schedulerCache := buildBestEffortCache(test.nodes, test.pods)
+		for _, name := range test.queues {
+			schedulerCache.AddQueue(&kbv1.Queue{
+				ObjectMeta: metav1.ObjectMeta{Name: name},
+				Spec:       kbv1.QueueSpec{Weight: 1},
+			})
+		}
+		for name, queue := range test.podGroups {
+			schedulerCache.AddPodGroup(&kbv1.PodGroup{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "c1"},
+				Spec:       kbv1.PodGroupSpec{Queue: queue, MinMember: 1},
+				Status:     kbv1.PodGroupStatus{Phase: kbv1.PodGroupInqueue},
+			})
+		}
+
+		trueValue := true
+		ssn := framework.OpenSession(schedulerCache, []conf.Tier{
+			{
+				Plugins: []conf.PluginOption{
+					{
+						Name:              "order",
+						EnabledNodeOrder:  &trueValue,
+						EnabledQueueOrder: &trueValue,
+					},
+				},
+			},
+		})
+
+		New().Execute(ssn)
+		for _, job := range ssn.Jobs {
+			for uid, task := range job.Tasks {
+				if expected, found := test.expected[string(uid)]; found && task.NodeName != expected {
+					t.Errorf("case %d (%s): expected task <%s> on node <%s>, got <%s>",
+						i, test.name, uid, expected, task.NodeName)
+				}
+			}
+		}
+
+		framework.CloseSession(ssn)
+	}
+}
+
+func TestBackfill(t *testing.T) {
+	framework.RegisterPluginBuilder("gang", gang.New)
+	defer framework.CleanupPluginBuilders()
+
+	tests := []struct {
+		name string
+		// expected runtime of the running job pg2 and the small job pg3
+		runningRuntime  string
+		backfillRuntime string
+		// whether the nodes are idle, so the blocked job fits now
+		idle     bool
+		reserved bool
+		// whether the task of small job pg3 is backfilled
+		backfilled bool
+	}{
+		{
+			name:            "task finishes before blocked job starts",
+			runningRuntime:  "1h",
+			backfillRuntime: "30m",
+			reserved:        true,
+			backfilled:      true,
+		},
+		{
+			name:            "task does not finish before blocked job starts",
+			runningRuntime:  "1h",
+			backfillRuntime: "2h",
+			reserved:        true,
+			backfilled:      false,
+		},
+		{
+			name:           "runtime of task is unknown",
+			runningRuntime: "1h",
+			reserved:       true,
+			backfilled:     false,
+		},
+		{
+			name:            "start of blocked job is unknown",
+			backfillRuntime: "2h",
+			reserved:        false,
+			backfilled:      true,
+		},
+		{
+			name:            "blocked job fits now",
+			runningRuntime:  "1h",
+			backfillRuntime: "2h",
+			idle:            true,
+			reserved:        false,
+			backfilled:      true,
+		},
+	}
+
+	for i, test := range tests {
+		schedulerCache := cache.NewOfflineCache("kube-batch", "q1")
+		schedulerCache.Evictor = &util.FakeEvictor{}
+		schedulerCache.StatusUpdater = &util.FakeStatusUpdater{}
+		schedulerCache.VolumeBinder = &util.FakeVolumeBinder{}
+		schedulerCache.Binder = &util.FakeBinder{
+			Binds:   map[string]string{},
+			Channel: make(chan string, 4),
+		}
+		schedulerCache.Recorder = record.NewFakeRecorder(100)
+
+		for _, name := range []string{"n1", "n2"} {
+			alloc := util.BuildResourceList("2", "2G")
+			alloc[v1.ResourcePods] = resource.MustParse("10")
+			schedulerCache.AddNode(util.BuildNode(name, alloc, make(map[string]string)))
+		}
+		schedulerCache.AddQueue(&kbv1.Queue{
+			ObjectMeta: metav1.ObjectMeta{Name: "q1"},
+			Spec:       kbv1.QueueSpec{Weight: 1},
+		})
+
+		// The gang pg1 is blocked until the tasks of pg2 finish on both nodes.
+		for _, pg := range []struct {
+			name      string
+			minMember int32
+			runtime   string
+		}{
+			{"pg1", 2, ""},
+			{"pg2", 1, test.runningRuntime},
+			{"pg3", 1, test.backfillRuntime},
+			{"pg4", 1, ""},
+		} {
+			annotations := map[string]string{}
+			if len(pg.runtime) != 0 {
+				annotations[kbv1.ExpectedRuntimeAnnotationKey] = pg.runtime
+			}
+			schedulerCache.AddPodGroup(&kbv1.PodGroup{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        pg.name,
+					Namespace:   "c1",
+					Annotations: annotations,
+				},
+				Spec:   kbv1.PodGroupSpec{Queue: "q1", MinMember: pg.minMember},
+				Status: kbv1.PodGroupStatus{Phase: kbv1.PodGroupInqueue},
+			})
+		}
+
+		// The BestEffort task p6 is backfilled regardless of the reservation.
+		for _, p := range []struct{ name, node, cpu, pg string }{
+			{"p1", "", "2", "pg1"},
+			{"p2", "", "2", "pg1"},
+			{"p3", "n1", "2", "pg2"},
+			{"p4", "n2", "1", "pg2"},
+			{"p5", "", "1", "pg3"},
+			{"p6", "", "", "pg4"},
+		} {
+			if test.idle && p.pg == "pg2" {
+				continue
+			}
+			phase := v1.PodPending
+			if len(p.node) != 0 {
+				phase = v1.PodRunning
+			}
+			resources := v1.ResourceList{}
+			if len(p.cpu) != 0 {
+				resources = util.BuildResourceList(p.cpu, "1G")
+			}
+			schedulerCache.AddPod(util.BuildPod("c1", p.name, p.node, phase, resources,
+				p.pg, make(map[string]string), make(map[string]string)))
+		}
+
+		trueValue := true
+		ssn := framework.OpenSession(schedulerCache, []conf.Tier{
+			{
+				Plugins: []conf.PluginOption{
+					{
+						Name:                "gang",
+						EnabledJobReady:     &trueValue,
+						EnabledJobPipelined: &trueValue,
+					},
+				},
+			},
+		})
+
+		New().Execute(ssn)
+		if reserved := ssn.Reservation != nil; reserved != test.reserved {
+			t.Errorf("case %d (%s): expected nodes reserved %t, got reservation %v",
+				i, test.name, test.reserved, ssn.Reservation)
+		}
+		if backfilled := len(ssn.Jobs["c1/pg3"].TaskStatusIndex[api.Pending]) == 0; backfilled != test.backfilled {
+			t.Errorf("case %d (%s): expected task <c1/p5> backfilled %t, got %t",
+				i, test.name, test.backfilled, backfilled)
+		}
+		if task := ssn.Jobs["c1/pg4"].Tasks["c1-p6"]; len(task.NodeName) == 0 {
+			t.Errorf("case %d (%s): expected BestEffort task <c1/p6> backfilled, got fit errors %v",
+				i, test.name, ssn.Jobs["c1/pg4"].NodesFitErrors[task.UID])
+		}
+
+		framework.CloseSession(ssn)
+	}
+}
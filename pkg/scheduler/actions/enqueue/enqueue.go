@@ -17,6 +17,8 @@ limitations under the License.
 package enqueue
 
 import (
+	"sort"
+
 	"github.com/golang/glog"
 
 	"github.com/kubernetes-sigs/kube-batch/pkg/apis/scheduling/v1alpha1"
@@ -25,8 +27,30 @@ import (
 	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/util"
 )
 
+const (
+	// OvercommitFactor is the key of the ratio of the resources admitted by enqueue to
+	// the allocatable resources of cluster, e.g. "1.2"
+	OvercommitFactor = "enqueue.overcommitFactor"
+	// QueueOvercommitFactorPrefix is the key prefix of the overcommit factor of a queue
+	// by its name, e.g. "enqueue.overcommitFactor.q1"
+	QueueOvercommitFactorPrefix = OvercommitFactor + "."
+	// EstimateMinResources is the key of whether to estimate the minimal resources of
+	// PodGroup from its pods if not set; otherwise the PodGroup is admitted anyway
+	EstimateMinResources = "enqueue.estimateMinResources"
+
+	defaultOvercommitFactor = 1.2
+)
+
+func init() {
+	framework.RegisterActionArguments("enqueue", OvercommitFactor, QueueOvercommitFactorPrefix,
+		EstimateMinResources)
+}
+
 type enqueueAction struct {
 	ssn *framework.Session
+
+	// arguments are the arguments of action in the configurations of scheduler.
+	arguments framework.Arguments
 }
 
 func New() *enqueueAction {
@@ -37,7 +61,9 @@ func (enqueue *enqueueAction) Name() string {
 	return "enqueue"
 }
 
-func (enqueue *enqueueAction) Initialize() {}
+func (enqueue *enqueueAction) Initialize(arguments framework.Arguments) {
+	enqueue.arguments = arguments
+}
 
 func (enqueue *enqueueAction) Execute(ssn *framework.Session) {
 	glog.V(3).Infof("Enter Enqueue ...")
@@ -64,6 +90,13 @@ func (enqueue *enqueueAction) Execute(ssn *framework.Session) {
 		}
 
 		if job.PodGroup.Status.Phase == v1alpha1.PodGroupPending {
+			// Only Open queue admits new PodGroups.
+			if queue := ssn.Queues[job.Queue]; queue.State != v1alpha1.QueueStateOpen {
+				glog.V(3).Infof("Queue <%s> is <%s>, ignore Job <%s/%s>.",
+					queue.Name, queue.State, job.Namespace, job.Name)
+				continue
+			}
+
 			if _, found := jobsMap[job.Queue]; !found {
 				jobsMap[job.Queue] = util.NewPriorityQueue(ssn.JobOrderFn)
 			}
@@ -74,10 +107,17 @@ func (enqueue *enqueueAction) Execute(ssn *framework.Session) {
 
 	glog.V(3).Infof("Try to enqueue PodGroup to %d Queues", len(jobsMap))
 
-	emptyRes := api.EmptyResource()
-	nodesIdleRes := api.EmptyResource()
+	args := enqueue.arguments
+	factor := defaultOvercommitFactor
+	args.GetFloat64(&factor, OvercommitFactor)
+	estimate := false
+	args.GetBool(&estimate, EstimateMinResources)
+
+	total := api.EmptyResource()
+	used := api.EmptyResource()
 	for _, node := range ssn.Nodes {
-		nodesIdleRes.Add(node.Allocatable.Clone().Multi(1.2).Sub(node.Used))
+		total.Add(node.Allocatable)
+		used.Add(node.Used)
 	}
 
 	for {
@@ -85,13 +125,18 @@ func (enqueue *enqueueAction) Execute(ssn *framework.Session) {
 			break
 		}
 
-		if nodesIdleRes.Less(emptyRes) {
-			glog.V(3).Infof("Node idle resource <%s> is overused, ignore it.")
-			break
-		}
-
 		queue := queues.Pop().(*api.QueueInfo)
 
+		// The resources admitted to the queue are limited by its overcommit factor.
+		queueFactor := factor
+		args.GetFloat64(&queueFactor, QueueOvercommitFactorPrefix+queue.Name)
+		budget := total.Clone().Multi(queueFactor)
+		if !used.LessEqual(budget) {
+			glog.V(3).Infof("Resource <%v> is overused by overcommit factor %v of Queue <%s>, ignore it.",
+				used, queueFactor, queue.Name)
+			continue
+		}
+
 		// Found "high" priority job
 		jobs, found := jobsMap[queue.UID]
 		if !found || jobs.Empty() {
@@ -101,14 +146,11 @@ func (enqueue *enqueueAction) Execute(ssn *framework.Session) {
 
 		inqueue := false
 
-		if job.PodGroup.Spec.MinResources == nil {
+		if minResources := minResources(ssn, job, estimate); minResources == nil {
+			inqueue = true
+		} else if ssn.JobEnqueueable(job) && used.Clone().Add(minResources).LessEqual(budget) {
+			used.Add(minResources)
 			inqueue = true
-		} else {
-			pgResource := api.NewResource(*job.PodGroup.Spec.MinResources)
-			if ssn.JobEnqueueable(job) && pgResource.LessEqual(nodesIdleRes) {
-				nodesIdleRes.Sub(pgResource)
-				inqueue = true
-			}
 		}
 
 		if inqueue {
@@ -121,4 +163,37 @@ func (enqueue *enqueueAction) Execute(ssn *framework.Session) {
 	}
 }
 
+// minResources returns the minimal resources of job to run; if not set by its PodGroup,
+// they are estimated by the resources of the first MinAvailable tasks in order when
+// estimate is true, or nil otherwise.
+func minResources(ssn *framework.Session, job *api.JobInfo, estimate bool) *api.Resource {
+	if job.PodGroup.Spec.MinResources != nil {
+		return api.NewResource(*job.PodGroup.Spec.MinResources)
+	}
+	if !estimate {
+		return nil
+	}
+
+	var tasks []*api.TaskInfo
+	for _, task := range job.Tasks {
+		tasks = append(tasks, task)
+	}
+	sort.Slice(tasks, func(i, j int) bool {
+		return ssn.TaskOrderFn(tasks[i], tasks[j])
+	})
+	if int(job.MinAvailable) < len(tasks) {
+		tasks = tasks[:job.MinAvailable]
+	}
+
+	resources := api.EmptyResource()
+	for _, task := range tasks {
+		resources.Add(task.Resreq)
+	}
+
+	glog.V(4).Infof("Estimated minimal resources <%v> of Job <%s/%s> from its <%d> tasks.",
+		resources, job.Namespace, job.Name, len(tasks))
+
+	return resources
+}
+
 func (enqueue *enqueueAction) UnInitialize() {}
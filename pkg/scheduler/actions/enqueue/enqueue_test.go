@@ -0,0 +1,110 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package enqueue
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	kbv1 "github.com/kubernetes-sigs/kube-batch/pkg/apis/scheduling/v1alpha1"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/cache"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/framework"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/util"
+)
+
+func TestEnqueue(t *testing.T) {
+	minResources := util.BuildResourceList("3", "1G")
+
+	tests := []struct {
+		name         string
+		arguments    framework.Arguments
+		minResources *v1.ResourceList
+		expected     kbv1.PodGroupPhase
+	}{
+		{
+			name:         "minimal resources exceed default overcommit",
+			minResources: &minResources,
+			expected:     kbv1.PodGroupPending,
+		},
+		{
+			name:         "overcommit factor of cluster",
+			arguments:    framework.Arguments{OvercommitFactor: "1.5"},
+			minResources: &minResources,
+			expected:     kbv1.PodGroupInqueue,
+		},
+		{
+			name: "overcommit factor of queue",
+			arguments: framework.Arguments{
+				OvercommitFactor:                   "1.5",
+				QueueOvercommitFactorPrefix + "q1": "1.0",
+			},
+			minResources: &minResources,
+			expected:     kbv1.PodGroupPending,
+		},
+		{
+			name:     "minimal resources not set",
+			expected: kbv1.PodGroupInqueue,
+		},
+		{
+			name:      "minimal resources estimated from pods",
+			arguments: framework.Arguments{EstimateMinResources: "true"},
+			expected:  kbv1.PodGroupPending,
+		},
+	}
+
+	for i, test := range tests {
+		schedulerCache := cache.NewOfflineCache("kube-batch", "q1")
+		schedulerCache.Evictor = &util.FakeEvictor{}
+		schedulerCache.StatusUpdater = &util.FakeStatusUpdater{}
+		schedulerCache.VolumeBinder = &util.FakeVolumeBinder{}
+		schedulerCache.Recorder = record.NewFakeRecorder(100)
+
+		schedulerCache.AddNode(util.BuildNode("n1", util.BuildResourceList("2", "2G"), make(map[string]string)))
+		schedulerCache.AddQueue(&kbv1.Queue{
+			ObjectMeta: metav1.ObjectMeta{Name: "q1"},
+			Spec:       kbv1.QueueSpec{Weight: 1},
+			Status:     kbv1.QueueStatus{State: kbv1.QueueStateOpen},
+		})
+		schedulerCache.AddPodGroup(&kbv1.PodGroup{
+			ObjectMeta: metav1.ObjectMeta{Name: "pg1", Namespace: "c1"},
+			Spec: kbv1.PodGroupSpec{
+				Queue:        "q1",
+				MinMember:    2,
+				MinResources: test.minResources,
+			},
+			Status: kbv1.PodGroupStatus{Phase: kbv1.PodGroupPending},
+		})
+		for _, name := range []string{"p1", "p2"} {
+			schedulerCache.AddPod(util.BuildPod("c1", name, "", v1.PodPending, util.BuildResourceList("1.5", "1G"),
+				"pg1", make(map[string]string), make(map[string]string)))
+		}
+
+		ssn := framework.OpenSession(schedulerCache, nil)
+
+		enqueue := New()
+		enqueue.Initialize(test.arguments)
+		enqueue.Execute(ssn)
+		if phase := ssn.Jobs["c1/pg1"].PodGroup.Status.Phase; phase != test.expected {
+			t.Errorf("case %d (%s): expected PodGroup <c1/pg1> %s, got %s", i, test.name, test.expected, phase)
+		}
+
+		framework.CloseSession(ssn)
+	}
+}
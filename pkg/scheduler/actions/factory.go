@@ -24,6 +24,7 @@ import (
 	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/actions/enqueue"
 	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/actions/preempt"
 	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/actions/reclaim"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/actions/reserve"
 )
 
 func init() {
@@ -32,4 +33,5 @@ func init() {
 	framework.RegisterAction(backfill.New())
 	framework.RegisterAction(preempt.New())
 	framework.RegisterAction(enqueue.New())
+	framework.RegisterAction(reserve.New())
 }
@@ -18,6 +18,8 @@ package preempt
 
 import (
 	"fmt"
+	"sort"
+	"strconv"
 
 	"github.com/golang/glog"
 
@@ -28,8 +30,21 @@ import (
 	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/util"
 )
 
+const (
+	// CrossQueuePriority is the key of the minimum priority of jobs which preempt the
+	// jobs of lower priority in other queues; cross-queue preemption is disabled if not set
+	CrossQueuePriority = "preempt.crossQueuePriority"
+)
+
+func init() {
+	framework.RegisterActionArguments("preempt", CrossQueuePriority)
+}
+
 type preemptAction struct {
 	ssn *framework.Session
+
+	// arguments are the arguments of action in the configurations of scheduler.
+	arguments framework.Arguments
 }
 
 func New() *preemptAction {
@@ -40,7 +55,9 @@ func (alloc *preemptAction) Name() string {
 	return "preempt"
 }
 
-func (alloc *preemptAction) Initialize() {}
+func (alloc *preemptAction) Initialize(arguments framework.Arguments) {
+	alloc.arguments = arguments
+}
 
 func (alloc *preemptAction) Execute(ssn *framework.Session) {
 	glog.V(3).Infof("Enter Preempt ...")
@@ -107,7 +124,7 @@ func (alloc *preemptAction) Execute(ssn *framework.Session) {
 
 				preemptor := preemptorTasks[preemptorJob.UID].Pop().(*api.TaskInfo)
 
-				if preempted, _ := preempt(ssn, stmt, preemptor, ssn.Nodes, func(task *api.TaskInfo) bool {
+				if preempted, _ := preempt(ssn, stmt, preemptor, ssn.Nodes, ssn.Preemptable, func(task *api.TaskInfo) bool {
 					// Ignore non running task.
 					if task.Status != api.Running {
 						return false
@@ -130,10 +147,17 @@ func (alloc *preemptAction) Execute(ssn *framework.Session) {
 				}
 			}
 
-			// If job is not pipelined after try all tasks, next job.
+			// If job is not pipelined after try all tasks, evict the gangs of lower
+			// priority as a whole; if still not pipelined, next job.
 			if !ssn.JobPipelined(preemptorJob) {
 				stmt.Discard()
-				continue
+
+				stmt = ssn.Statement()
+				if !preemptGangs(ssn, stmt, preemptorJob) {
+					stmt.Discard()
+					continue
+				}
+				stmt.Commit()
 			}
 
 			if assigned {
@@ -155,7 +179,7 @@ func (alloc *preemptAction) Execute(ssn *framework.Session) {
 				preemptor := preemptorTasks[job.UID].Pop().(*api.TaskInfo)
 
 				stmt := ssn.Statement()
-				assigned, _ := preempt(ssn, stmt, preemptor, ssn.Nodes, func(task *api.TaskInfo) bool {
+				assigned, _ := preempt(ssn, stmt, preemptor, ssn.Nodes, ssn.Preemptable, func(task *api.TaskInfo) bool {
 					// Ignore non running task.
 					if task.Status != api.Running {
 						return false
@@ -173,6 +197,94 @@ func (alloc *preemptAction) Execute(ssn *framework.Session) {
 			}
 		}
 	}
+
+	// Preemption between Jobs across Queues, only for the jobs whose priority is
+	// not less than the threshold in arguments.
+	if minPriority, enabled := crossQueuePriority(alloc.arguments); enabled {
+		preemptAcrossQueues(ssn, underRequest, minPriority)
+	}
+}
+
+// crossQueuePriority returns the minimum priority of jobs which preempt across
+// queues, and whether cross-queue preemption is enabled.
+func crossQueuePriority(args framework.Arguments) (int32, bool) {
+	argv, found := args[CrossQueuePriority]
+	if !found || argv == "" {
+		return 0, false
+	}
+
+	priority, err := strconv.ParseInt(argv, 10, 32)
+	if err != nil {
+		glog.Warningf("Could not parse argument: %s for key %s, with err %v", argv, CrossQueuePriority, err)
+		return 0, false
+	}
+
+	return int32(priority), true
+}
+
+// preemptAcrossQueues preempts the running jobs of lower priority in other queues
+// for the jobs whose priority is not less than minPriority. The victims are also
+// checked by ssn.Reclaimable, so the queues of victims are not reclaimed below their
+// guaranteed or deserved resources, e.g. reported by proportion plugin.
+func preemptAcrossQueues(ssn *framework.Session, jobs []*api.JobInfo, minPriority int32) {
+	preemptors := util.NewPriorityQueue(ssn.JobOrderFn)
+	preemptorTasks := map[api.JobID]*util.PriorityQueue{}
+	for _, job := range jobs {
+		if job.Priority < minPriority || len(job.TaskStatusIndex[api.Pending]) == 0 {
+			continue
+		}
+		preemptors.Push(job)
+		preemptorTasks[job.UID] = util.NewPriorityQueue(ssn.TaskOrderFn)
+		for _, task := range job.TaskStatusIndex[api.Pending] {
+			preemptorTasks[job.UID].Push(task)
+		}
+	}
+
+	victimsFn := func(preemptor *api.TaskInfo, preemptees []*api.TaskInfo) []*api.TaskInfo {
+		return ssn.Reclaimable(preemptor, ssn.Preemptable(preemptor, preemptees))
+	}
+
+	for !preemptors.Empty() {
+		preemptorJob := preemptors.Pop().(*api.JobInfo)
+
+		stmt := ssn.Statement()
+		assigned := false
+		for !preemptorTasks[preemptorJob.UID].Empty() {
+			preemptor := preemptorTasks[preemptorJob.UID].Pop().(*api.TaskInfo)
+
+			if preempted, _ := preempt(ssn, stmt, preemptor, ssn.Nodes, victimsFn, func(task *api.TaskInfo) bool {
+				// Ignore non running task.
+				if task.Status != api.Running {
+					return false
+				}
+
+				job, found := ssn.Jobs[task.Job]
+				if !found {
+					return false
+				}
+				// Preempt the jobs of lower priority in other queues.
+				return job.Queue != preemptorJob.Queue && job.Priority < preemptorJob.Priority
+			}); preempted {
+				assigned = true
+			}
+
+			// If job is not pipelined, keep preempting
+			if ssn.JobPipelined(preemptorJob) {
+				stmt.Commit()
+				break
+			}
+		}
+
+		// If job is not pipelined after try all tasks, next job.
+		if !ssn.JobPipelined(preemptorJob) {
+			stmt.Discard()
+			continue
+		}
+
+		if assigned {
+			preemptors.Push(preemptorJob)
+		}
+	}
 }
 
 func (alloc *preemptAction) UnInitialize() {}
@@ -182,15 +294,21 @@ func preempt(
 	stmt *framework.Statement,
 	preemptor *api.TaskInfo,
 	nodes map[string]*api.NodeInfo,
+	victimsFn func(*api.TaskInfo, []*api.TaskInfo) []*api.TaskInfo,
 	filter func(*api.TaskInfo) bool,
 ) (bool, error) {
-	assigned := false
-
 	allNodes := util.GetNodeList(nodes)
 
 	predicateNodes, _ := util.PredicateNodes(preemptor, allNodes, ssn.PredicateFn)
 
 	nodeScores := util.PrioritizeNodes(preemptor, predicateNodes, ssn.BatchNodeOrderFn, ssn.NodeOrderMapFn, ssn.NodeOrderReduceFn)
+	ssn.RecordNodeScores(preemptor, nodeScores)
+
+	// Find the node with the cheapest victims, the nodes with higher scores are
+	// preferred if the costs are the same.
+	var bestNode *api.NodeInfo
+	var bestVictims []*api.TaskInfo
+	var bestCost *framework.VictimsCost
 
 	selectedNodes := util.SortNodes(nodeScores)
 	for _, node := range selectedNodes {
@@ -198,9 +316,6 @@ func preempt(
 			preemptor.Namespace, preemptor.Name, node.Name)
 
 		var preemptees []*api.TaskInfo
-		preempted := api.EmptyResource()
-		resreq := preemptor.InitResreq.Clone()
-
 		for _, task := range node.Tasks {
 			if filter == nil {
 				preemptees = append(preemptees, task.Clone())
@@ -208,55 +323,153 @@ func preempt(
 				preemptees = append(preemptees, task.Clone())
 			}
 		}
-		victims := ssn.Preemptable(preemptor, preemptees)
+		victims := victimsFn(preemptor, preemptees)
 		metrics.UpdatePreemptionVictimsCount(len(victims))
 
-		if err := validateVictims(victims, resreq); err != nil {
+		if err := validateVictims(victims, preemptor.InitResreq); err != nil {
 			glog.V(3).Infof("No validated victims on Node <%s>: %v", node.Name, err)
 			continue
 		}
 
-		victimsQueue := util.NewPriorityQueue(func(l, r interface{}) bool {
-			return !ssn.TaskOrderFn(l, r)
-		})
-		for _, victim := range victims {
-			victimsQueue.Push(victim)
+		victims, cost := ssn.SelectVictims(preemptor, node, victims)
+		if victims == nil {
+			glog.V(3).Infof("Task <%s/%s> does not fit Node <%s> after preemption.",
+				preemptor.Namespace, preemptor.Name, node.Name)
+			continue
 		}
-		// Preempt victims for tasks, pick lowest priority task first.
-		for !victimsQueue.Empty() {
-			preemptee := victimsQueue.Pop().(*api.TaskInfo)
-			glog.Errorf("Try to preempt Task <%s/%s> for Tasks <%s/%s>",
-				preemptee.Namespace, preemptee.Name, preemptor.Namespace, preemptor.Name)
-			if err := stmt.Evict(preemptee, "preempt"); err != nil {
-				glog.Errorf("Failed to preempt Task <%s/%s> for Tasks <%s/%s>: %v",
-					preemptee.Namespace, preemptee.Name, preemptor.Namespace, preemptor.Name, err)
-				continue
-			}
-			preempted.Add(preemptee.Resreq)
-			// If reclaimed enough resources, break loop to avoid Sub panic.
-			if resreq.LessEqual(preempted) {
-				break
-			}
+		glog.V(3).Infof("Preempting <%d> Tasks on Node <%s> for Task <%s/%s> costs: %v.",
+			len(victims), node.Name, preemptor.Namespace, preemptor.Name, cost)
+
+		if bestCost == nil || cost.Less(bestCost) {
+			bestNode, bestVictims, bestCost = node, victims, cost
 		}
+	}
 
-		metrics.RegisterPreemptionAttempts()
-		glog.V(3).Infof("Preempted <%v> for task <%s/%s> requested <%v>.",
-			preempted, preemptor.Namespace, preemptor.Name, preemptor.InitResreq)
+	if bestNode == nil {
+		return false, nil
+	}
+
+	metrics.RegisterPreemptionAttempts()
+
+	preempted := api.EmptyResource()
+	for _, preemptee := range bestVictims {
+		glog.Errorf("Try to preempt Task <%s/%s> for Tasks <%s/%s>",
+			preemptee.Namespace, preemptee.Name, preemptor.Namespace, preemptor.Name)
+		reason := fmt.Sprintf("Preempted by Task <%s/%s>", preemptor.Namespace, preemptor.Name)
+		if err := stmt.Evict(preemptee, reason); err != nil {
+			glog.Errorf("Failed to preempt Task <%s/%s> for Tasks <%s/%s>: %v",
+				preemptee.Namespace, preemptee.Name, preemptor.Namespace, preemptor.Name, err)
+			continue
+		}
+		preempted.Add(preemptee.Resreq)
+	}
+
+	glog.V(3).Infof("Preempted <%v> for task <%s/%s> requested <%v>.",
+		preempted, preemptor.Namespace, preemptor.Name, preemptor.InitResreq)
 
-		if preemptor.InitResreq.LessEqual(preempted) {
-			if err := stmt.Pipeline(preemptor, node.Name); err != nil {
-				glog.Errorf("Failed to pipline Task <%s/%s> on Node <%s>",
-					preemptor.Namespace, preemptor.Name, node.Name)
+	if err := stmt.Pipeline(preemptor, bestNode.Name); err != nil {
+		glog.Errorf("Failed to pipline Task <%s/%s> on Node <%s>",
+			preemptor.Namespace, preemptor.Name, bestNode.Name)
+	}
+
+	// Ignore pipeline error, will be corrected in next scheduling loop.
+	return true, nil
+}
+
+// preemptGangs evicts the jobs of lower priority in the queue of preemptor job as
+// a whole, the cheapest first, until the preemptor job is pipelined. The jobs whose
+// ready tasks are not all running or preemptable are not evicted.
+func preemptGangs(ssn *framework.Session, stmt *framework.Statement, preemptorJob *api.JobInfo) bool {
+	pending := preemptorJob.TaskStatusIndex[api.Pending]
+	if len(pending) == 0 {
+		return false
+	}
+	var preemptor *api.TaskInfo
+	for _, task := range pending {
+		if preemptor == nil || ssn.TaskOrderFn(task, preemptor) {
+			preemptor = task
+		}
+	}
+
+	var gangs []*api.JobInfo
+	for _, job := range ssn.Jobs {
+		if job.UID == preemptorJob.UID || job.Queue != preemptorJob.Queue ||
+			job.Priority >= preemptorJob.Priority || job.ReadyTaskNum() == 0 {
+			continue
+		}
+		gangs = append(gangs, job)
+	}
+	sort.Slice(gangs, func(i, j int) bool {
+		if gangs[i].Priority != gangs[j].Priority {
+			return gangs[i].Priority < gangs[j].Priority
+		}
+		if ri, rj := gangs[i].ReadyTaskNum(), gangs[j].ReadyTaskNum(); ri != rj {
+			return ri < rj
+		}
+		return gangs[i].UID < gangs[j].UID
+	})
+
+	for _, gang := range gangs {
+		var preemptees []*api.TaskInfo
+		for _, task := range gang.TaskStatusIndex[api.Running] {
+			// Clone task to avoid modify Task's status on node.
+			preemptees = append(preemptees, task.Clone())
+		}
+		if int32(len(preemptees)) < gang.ReadyTaskNum() {
+			glog.V(3).Infof("Can not preempt Job <%s/%s> as a whole, not all ready tasks are running.",
+				gang.Namespace, gang.Name)
+			continue
+		}
+		if victims := ssn.Preemptable(preemptor, preemptees); len(victims) != len(preemptees) {
+			glog.V(3).Infof("Can not preempt Job <%s/%s> as a whole, <%d/%d> tasks are preemptable.",
+				gang.Namespace, gang.Name, len(victims), len(preemptees))
+			continue
+		}
+
+		reason := fmt.Sprintf("Preempted as a gang by Job <%s/%s>", preemptorJob.Namespace, preemptorJob.Name)
+		for _, preemptee := range preemptees {
+			glog.V(3).Infof("Try to preempt Task <%s/%s> of gang for Job <%s/%s>",
+				preemptee.Namespace, preemptee.Name, preemptorJob.Namespace, preemptorJob.Name)
+			if err := stmt.Evict(preemptee, reason); err != nil {
+				glog.Errorf("Failed to preempt Task <%s/%s> for Job <%s/%s>: %v",
+					preemptee.Namespace, preemptee.Name, preemptorJob.Namespace, preemptorJob.Name, err)
+				return false
 			}
+		}
+		metrics.RegisterPreemptionAttempts()
 
-			// Ignore pipeline error, will be corrected in next scheduling loop.
-			assigned = true
+		pipelineTasks(ssn, stmt, preemptorJob)
+		if ssn.JobPipelined(preemptorJob) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// pipelineTasks pipelines the pending tasks of job on the resources released on nodes.
+func pipelineTasks(ssn *framework.Session, stmt *framework.Statement, job *api.JobInfo) {
+	tasks := util.NewPriorityQueue(ssn.TaskOrderFn)
+	for _, task := range job.TaskStatusIndex[api.Pending] {
+		tasks.Push(task)
+	}
 
+	for !tasks.Empty() {
+		task := tasks.Pop().(*api.TaskInfo)
+		for _, node := range util.GetNodeList(ssn.Nodes) {
+			if !task.InitResreq.LessEqual(node.Releasing) {
+				continue
+			}
+			if err := ssn.PredicateFn(task, node); err != nil {
+				continue
+			}
+			if err := stmt.Pipeline(task, node.Name); err != nil {
+				glog.Errorf("Failed to pipeline Task <%s/%s> on Node <%s>",
+					task.Namespace, task.Name, node.Name)
+			}
 			break
 		}
 	}
-
-	return assigned, nil
 }
 
 func validateVictims(victims []*api.TaskInfo, resreq *api.Resource) error {
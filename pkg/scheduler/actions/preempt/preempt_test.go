@@ -17,10 +17,15 @@ limitations under the License.
 package preempt
 
 import (
+	"reflect"
+	"sort"
+	"sync"
 	"testing"
 	"time"
 
 	v1 "k8s.io/api/core/v1"
+	schedulingv1beta1 "k8s.io/api/scheduling/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/tools/record"
 
@@ -31,6 +36,7 @@ import (
 	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/framework"
 	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/plugins/conformance"
 	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/plugins/gang"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/plugins/proportion"
 	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/util"
 )
 
@@ -140,7 +146,7 @@ func TestPreempt(t *testing.T) {
 		}
 		evictor := &util.FakeEvictor{
 			Evicts:  make([]string, 0),
-			Channel: make(chan string),
+			Channel: make(chan string, 10),
 		}
 		schedulerCache := &cache.SchedulerCache{
 			Nodes:         make(map[string]*api.NodeInfo),
@@ -200,3 +206,427 @@ func TestPreempt(t *testing.T) {
 		}
 	}
 }
+
+func TestPreemptCheapestVictims(t *testing.T) {
+	framework.RegisterPluginBuilder("conformance", conformance.New)
+	framework.RegisterPluginBuilder("gang", gang.New)
+	defer framework.CleanupPluginBuilders()
+
+	buildVictim := func(name, node, cpu string, priority int32, started time.Duration) *v1.Pod {
+		pod := util.BuildPod("c1", name, node, v1.PodRunning, util.BuildResourceList(cpu, "1G"),
+			"pg1", make(map[string]string), make(map[string]string))
+		pod.Spec.Priority = &priority
+		startTime := metav1.NewTime(time.Now().Add(-started))
+		pod.Status.StartTime = &startTime
+		return pod
+	}
+
+	tests := []struct {
+		name     string
+		pods     []*v1.Pod
+		expected []string
+	}{
+		{
+			// On n1, p1 is taken first as the cheapest, but left out as p2 alone is enough.
+			name: "unnecessary victims are left out",
+			pods: []*v1.Pod{
+				buildVictim("p1", "n1", "1", 1, time.Hour),
+				buildVictim("p2", "n1", "2", 2, time.Hour),
+				buildVictim("p3", "n2", "1", 5, time.Hour),
+				buildVictim("p4", "n2", "2", 5, time.Hour),
+			},
+			expected: []string{"c1/p2"},
+		},
+		{
+			name: "node with least running time lost",
+			pods: []*v1.Pod{
+				buildVictim("p1", "n1", "2", 1, 10*time.Hour),
+				buildVictim("p2", "n1", "1", 1, time.Hour),
+				buildVictim("p3", "n2", "2", 1, time.Minute),
+				buildVictim("p4", "n2", "1", 1, time.Hour),
+			},
+			expected: []string{"c1/p3"},
+		},
+	}
+
+	for i, test := range tests {
+		evictor := &util.FakeEvictor{
+			Evicts:  make([]string, 0),
+			Channel: make(chan string, 4),
+		}
+		schedulerCache := cache.NewOfflineCache("kube-batch", "q1")
+		schedulerCache.Binder = &util.FakeBinder{
+			Binds:   map[string]string{},
+			Channel: make(chan string, 4),
+		}
+		schedulerCache.Evictor = evictor
+		schedulerCache.StatusUpdater = &util.FakeStatusUpdater{}
+		schedulerCache.VolumeBinder = &util.FakeVolumeBinder{}
+		schedulerCache.Recorder = record.NewFakeRecorder(100)
+
+		for _, name := range []string{"n1", "n2"} {
+			schedulerCache.AddNode(util.BuildNode(name, util.BuildResourceList("3", "3G"), make(map[string]string)))
+		}
+		schedulerCache.AddQueue(&kbv1.Queue{
+			ObjectMeta: metav1.ObjectMeta{Name: "q1"},
+			Spec:       kbv1.QueueSpec{Weight: 1},
+		})
+		for _, name := range []string{"pg1", "pg2"} {
+			schedulerCache.AddPodGroup(&kbv1.PodGroup{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "c1"},
+				Spec:       kbv1.PodGroupSpec{Queue: "q1"},
+			})
+		}
+		for _, pod := range test.pods {
+			schedulerCache.AddPod(pod)
+		}
+		schedulerCache.AddPod(util.BuildPod("c1", "preemptor", "", v1.PodPending, util.BuildResourceList("2", "1G"),
+			"pg2", make(map[string]string), make(map[string]string)))
+
+		trueValue := true
+		ssn := framework.OpenSession(schedulerCache, []conf.Tier{
+			{
+				Plugins: []conf.PluginOption{
+					{
+						Name:               "conformance",
+						EnabledPreemptable: &trueValue,
+					},
+					{
+						Name:               "gang",
+						EnabledPreemptable: &trueValue,
+					},
+				},
+			},
+		})
+
+		New().Execute(ssn)
+		framework.CloseSession(ssn)
+
+		for range test.expected {
+			select {
+			case <-evictor.Channel:
+			case <-time.After(3 * time.Second):
+				t.Errorf("case %d (%s): failed to get evicting request", i, test.name)
+			}
+		}
+
+		evictor.Lock()
+		if !reflect.DeepEqual(test.expected, evictor.Evicts) {
+			t.Errorf("case %d (%s): expected evictions %v, got %v", i, test.name, test.expected, evictor.Evicts)
+		}
+		evictor.Unlock()
+	}
+}
+
+func TestPreemptWholeGang(t *testing.T) {
+	framework.RegisterPluginBuilder("conformance", conformance.New)
+	framework.RegisterPluginBuilder("gang", gang.New)
+	defer framework.CleanupPluginBuilders()
+
+	tests := []struct {
+		name      string
+		arguments map[string]string
+		expected  []string
+	}{
+		{
+			name:     "gang is not preempted by default",
+			expected: nil,
+		},
+		{
+			name:      "gang of lower priority is preempted as a whole",
+			arguments: map[string]string{gang.WholeGangPreemption: "true"},
+			expected:  []string{"c1/low1", "c1/low2"},
+		},
+	}
+
+	for i, test := range tests {
+		evictor := &util.FakeEvictor{
+			Evicts:  make([]string, 0),
+			Channel: make(chan string, 4),
+		}
+		schedulerCache := cache.NewOfflineCache("kube-batch", "q1")
+		schedulerCache.Evictor = evictor
+		schedulerCache.StatusUpdater = &util.FakeStatusUpdater{}
+		schedulerCache.VolumeBinder = &util.FakeVolumeBinder{}
+		schedulerCache.Recorder = record.NewFakeRecorder(100)
+
+		schedulerCache.AddNode(util.BuildNode("n1", util.BuildResourceList("4", "4G"), make(map[string]string)))
+		schedulerCache.AddQueue(&kbv1.Queue{
+			ObjectMeta: metav1.ObjectMeta{Name: "q1"},
+			Spec:       kbv1.QueueSpec{Weight: 1},
+		})
+		for name, value := range map[string]int32{"low": 1, "high": 100} {
+			schedulerCache.AddPriorityClass(&schedulingv1beta1.PriorityClass{
+				ObjectMeta: metav1.ObjectMeta{Name: name},
+				Value:      value,
+			})
+		}
+		for _, pg := range []struct{ name, priorityClass string }{{"pg1", "low"}, {"pg2", "high"}} {
+			schedulerCache.AddPodGroup(&kbv1.PodGroup{
+				ObjectMeta: metav1.ObjectMeta{Name: pg.name, Namespace: "c1"},
+				Spec:       kbv1.PodGroupSpec{Queue: "q1", MinMember: 2, PriorityClassName: pg.priorityClass},
+				Status:     kbv1.PodGroupStatus{Phase: kbv1.PodGroupRunning},
+			})
+		}
+		for _, name := range []string{"low1", "low2"} {
+			schedulerCache.AddPod(util.BuildPod("c1", name, "n1", v1.PodRunning, util.BuildResourceList("2", "2G"),
+				"pg1", make(map[string]string), make(map[string]string)))
+		}
+		for _, name := range []string{"high1", "high2"} {
+			schedulerCache.AddPod(util.BuildPod("c1", name, "", v1.PodPending, util.BuildResourceList("2", "2G"),
+				"pg2", make(map[string]string), make(map[string]string)))
+		}
+
+		trueValue := true
+		ssn := framework.OpenSession(schedulerCache, []conf.Tier{
+			{
+				Plugins: []conf.PluginOption{
+					{
+						Name:               "conformance",
+						EnabledPreemptable: &trueValue,
+					},
+					{
+						Name:                "gang",
+						EnabledPreemptable:  &trueValue,
+						EnabledJobPipelined: &trueValue,
+						Arguments:           test.arguments,
+					},
+				},
+			},
+		})
+
+		New().Execute(ssn)
+
+		pipelined := 0
+		for _, task := range ssn.Jobs["c1/pg2"].Tasks {
+			if task.Status == api.Pipelined {
+				pipelined++
+			}
+		}
+		if expected := len(test.expected); pipelined != expected {
+			t.Errorf("case %d (%s): expected %d tasks pipelined, got %d", i, test.name, expected, pipelined)
+		}
+		framework.CloseSession(ssn)
+
+		for range test.expected {
+			select {
+			case <-evictor.Channel:
+			case <-time.After(3 * time.Second):
+				t.Errorf("case %d (%s): failed to get evicting request", i, test.name)
+			}
+		}
+
+		evictor.Lock()
+		evicts := evictor.Evicts
+		evictor.Unlock()
+		sort.Strings(evicts)
+		if len(test.expected) != len(evicts) || (len(evicts) != 0 && !reflect.DeepEqual(test.expected, evicts)) {
+			t.Errorf("case %d (%s): expected evictions %v, got %v", i, test.name, test.expected, evicts)
+		}
+	}
+}
+
+func TestPreemptCrossQueue(t *testing.T) {
+	framework.RegisterPluginBuilder("conformance", conformance.New)
+	framework.RegisterPluginBuilder("gang", gang.New)
+	framework.RegisterPluginBuilder("proportion", proportion.New)
+	defer framework.CleanupPluginBuilders()
+
+	tests := []struct {
+		name      string
+		arguments map[string]string
+		guarantee v1.ResourceList
+		// the number of evicted tasks, any of which may be picked by proportion
+		expected int
+	}{
+		{
+			name:     "no cross-queue preemption by default",
+			expected: 0,
+		},
+		{
+			name:      "preempt the overused queue down to its deserved",
+			arguments: map[string]string{CrossQueuePriority: "1000"},
+			expected:  1,
+		},
+		{
+			name:      "priority of preemptor is under threshold",
+			arguments: map[string]string{CrossQueuePriority: "2000"},
+			expected:  0,
+		},
+		{
+			name:      "guarantee of victim queue is kept",
+			arguments: map[string]string{CrossQueuePriority: "1000"},
+			guarantee: util.BuildResourceList("4", "4G"),
+			expected:  0,
+		},
+	}
+
+	for i, test := range tests {
+		evictor := &util.FakeEvictor{
+			Evicts:  make([]string, 0),
+			Channel: make(chan string, 4),
+		}
+		schedulerCache := cache.NewOfflineCache("kube-batch", "q1")
+		schedulerCache.Evictor = evictor
+		schedulerCache.StatusUpdater = &util.FakeStatusUpdater{}
+		schedulerCache.VolumeBinder = &util.FakeVolumeBinder{}
+		schedulerCache.Recorder = record.NewFakeRecorder(100)
+
+		schedulerCache.AddNode(util.BuildNode("n1", util.BuildResourceList("4", "4G"), make(map[string]string)))
+		schedulerCache.AddQueue(&kbv1.Queue{
+			ObjectMeta: metav1.ObjectMeta{Name: "q1"},
+			Spec:       kbv1.QueueSpec{Weight: 1},
+		})
+		schedulerCache.AddQueue(&kbv1.Queue{
+			ObjectMeta: metav1.ObjectMeta{Name: "q2"},
+			Spec:       kbv1.QueueSpec{Weight: 1, Guarantee: test.guarantee},
+		})
+		for name, value := range map[string]int32{"low": 1, "critical": 1000} {
+			schedulerCache.AddPriorityClass(&schedulingv1beta1.PriorityClass{
+				ObjectMeta: metav1.ObjectMeta{Name: name},
+				Value:      value,
+			})
+		}
+		for _, pg := range []struct{ name, queue, priorityClass string }{
+			{"pg1", "q2", "low"},
+			{"pg2", "q1", "critical"},
+		} {
+			schedulerCache.AddPodGroup(&kbv1.PodGroup{
+				ObjectMeta: metav1.ObjectMeta{Name: pg.name, Namespace: "c1"},
+				Spec:       kbv1.PodGroupSpec{Queue: pg.queue, MinMember: 1, PriorityClassName: pg.priorityClass},
+				Status:     kbv1.PodGroupStatus{Phase: kbv1.PodGroupRunning},
+			})
+		}
+		for _, name := range []string{"low1", "low2", "low3", "low4"} {
+			schedulerCache.AddPod(util.BuildPod("c1", name, "n1", v1.PodRunning, util.BuildResourceList("1", "1G"),
+				"pg1", make(map[string]string), make(map[string]string)))
+		}
+		schedulerCache.AddPod(util.BuildPod("c1", "critical1", "", v1.PodPending, util.BuildResourceList("1", "1G"),
+			"pg2", make(map[string]string), make(map[string]string)))
+
+		trueValue := true
+		ssn := framework.OpenSession(schedulerCache, []conf.Tier{
+			{
+				Plugins: []conf.PluginOption{
+					{
+						Name:               "conformance",
+						EnabledPreemptable: &trueValue,
+						EnabledReclaimable: &trueValue,
+					},
+					{
+						Name:                "gang",
+						EnabledPreemptable:  &trueValue,
+						EnabledReclaimable:  &trueValue,
+						EnabledJobPipelined: &trueValue,
+					},
+					{
+						Name:               "proportion",
+						EnabledReclaimable: &trueValue,
+					},
+				},
+			},
+		})
+
+		preempt := New()
+		preempt.Initialize(test.arguments)
+		preempt.Execute(ssn)
+		framework.CloseSession(ssn)
+
+		for j := 0; j < test.expected; j++ {
+			select {
+			case <-evictor.Channel:
+			case <-time.After(3 * time.Second):
+				t.Errorf("case %d (%s): failed to get evicting request", i, test.name)
+			}
+		}
+
+		evictor.Lock()
+		evicts := evictor.Evicts
+		evictor.Unlock()
+		if len(evicts) != test.expected {
+			t.Errorf("case %d (%s): expected %d evictions, got %v", i, test.name, test.expected, evicts)
+		}
+	}
+}
+
+// rejectingEvictor rejects all evictions with 429, as API server does if the
+// eviction violates PodDisruptionBudget.
+type rejectingEvictor struct {
+	sync.Mutex
+	evicts []string
+}
+
+func (re *rejectingEvictor) Evict(p *v1.Pod, gracePeriodSeconds *int64) error {
+	re.Lock()
+	defer re.Unlock()
+
+	re.evicts = append(re.evicts, p.Namespace+"/"+p.Name)
+	return apierrors.NewTooManyRequests("Cannot evict pod as it would violate the pod's disruption budget.", 0)
+}
+
+func TestPreemptEvictionRejected(t *testing.T) {
+	framework.RegisterPluginBuilder("conformance", conformance.New)
+	framework.RegisterPluginBuilder("gang", gang.New)
+	defer framework.CleanupPluginBuilders()
+
+	evictor := &rejectingEvictor{}
+	schedulerCache := cache.NewOfflineCache("kube-batch", "q1")
+	schedulerCache.Evictor = evictor
+	schedulerCache.StatusUpdater = &util.FakeStatusUpdater{}
+	schedulerCache.VolumeBinder = &util.FakeVolumeBinder{}
+	schedulerCache.Recorder = record.NewFakeRecorder(100)
+
+	schedulerCache.AddNode(util.BuildNode("n1", util.BuildResourceList("3", "3G"), make(map[string]string)))
+	schedulerCache.AddQueue(&kbv1.Queue{
+		ObjectMeta: metav1.ObjectMeta{Name: "q1"},
+		Spec:       kbv1.QueueSpec{Weight: 1},
+	})
+	for _, name := range []string{"pg1", "pg2"} {
+		schedulerCache.AddPodGroup(&kbv1.PodGroup{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "c1"},
+			Spec:       kbv1.PodGroupSpec{Queue: "q1"},
+		})
+	}
+	for _, p := range []struct{ name, cpu string }{{"p1", "1"}, {"p2", "2"}} {
+		schedulerCache.AddPod(util.BuildPod("c1", p.name, "n1", v1.PodRunning, util.BuildResourceList(p.cpu, "1G"),
+			"pg1", make(map[string]string), make(map[string]string)))
+	}
+	schedulerCache.AddPod(util.BuildPod("c1", "preemptor", "", v1.PodPending, util.BuildResourceList("2", "1G"),
+		"pg2", make(map[string]string), make(map[string]string)))
+
+	trueValue := true
+	ssn := framework.OpenSession(schedulerCache, []conf.Tier{
+		{
+			Plugins: []conf.PluginOption{
+				{
+					Name:               "conformance",
+					EnabledPreemptable: &trueValue,
+				},
+				{
+					Name:               "gang",
+					EnabledPreemptable: &trueValue,
+				},
+			},
+		},
+	})
+	defer framework.CloseSession(ssn)
+
+	New().Execute(ssn)
+
+	evictor.Lock()
+	defer evictor.Unlock()
+	if expected := []string{"c1/p2"}; !reflect.DeepEqual(expected, evictor.evicts) {
+		t.Errorf("expected evictions %v, got %v", expected, evictor.evicts)
+	}
+
+	// The preemptor is not pipelined onto the resources which are not released.
+	if task := ssn.Jobs["c1/pg2"].Tasks["c1-preemptor"]; task.Status != api.Pending {
+		t.Errorf("expected task <c1/preemptor> %v, got %v", api.Pending, task.Status)
+	}
+	if task := ssn.Jobs["c1/pg1"].Tasks["c1-p2"]; task.Status != api.Running {
+		t.Errorf("expected task <c1/p2> %v, got %v", api.Running, task.Status)
+	}
+	if releasing := ssn.Nodes["n1"].Releasing; !releasing.IsEmpty() {
+		t.Errorf("expected no resources releasing on node <n1>, got %v", releasing)
+	}
+}
@@ -17,6 +17,8 @@ limitations under the License.
 package reclaim
 
 import (
+	"fmt"
+
 	"github.com/golang/glog"
 
 	"github.com/kubernetes-sigs/kube-batch/pkg/apis/scheduling/v1alpha1"
@@ -25,6 +27,10 @@ import (
 	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/util"
 )
 
+func init() {
+	framework.RegisterActionArguments("reclaim")
+}
+
 type reclaimAction struct {
 	ssn *framework.Session
 }
@@ -37,7 +43,7 @@ func (alloc *reclaimAction) Name() string {
 	return "reclaim"
 }
 
-func (alloc *reclaimAction) Initialize() {}
+func (alloc *reclaimAction) Initialize(framework.Arguments) {}
 
 func (alloc *reclaimAction) Execute(ssn *framework.Session) {
 	glog.V(3).Infof("Enter Reclaim ...")
@@ -118,16 +124,17 @@ func (alloc *reclaimAction) Execute(ssn *framework.Session) {
 			task = tasks.Pop().(*api.TaskInfo)
 		}
 
-		assigned := false
-		for _, n := range ssn.Nodes {
+		// Find the node with the cheapest victims.
+		var bestNode *api.NodeInfo
+		var bestVictims []*api.TaskInfo
+		var bestCost *framework.VictimsCost
+
+		for _, n := range util.GetNodeList(ssn.Nodes) {
 			// If predicates failed, next node.
 			if err := ssn.PredicateFn(task, n); err != nil {
 				continue
 			}
 
-			resreq := task.InitResreq.Clone()
-			reclaimed := api.EmptyResource()
-
 			glog.V(3).Infof("Considering Task <%s/%s> on Node <%s>.",
 				task.Namespace, task.Name, n.Name)
 
@@ -152,45 +159,51 @@ func (alloc *reclaimAction) Execute(ssn *framework.Session) {
 				continue
 			}
 
-			// If not enough resource, continue
-			allRes := api.EmptyResource()
-			for _, v := range victims {
-				allRes.Add(v.Resreq)
-			}
-			if allRes.Less(resreq) {
+			victims, cost := ssn.SelectVictims(task, n, victims)
+			if victims == nil {
 				glog.V(3).Infof("Not enough resource from victims on Node <%s>.", n.Name)
 				continue
 			}
+			glog.V(3).Infof("Reclaiming <%d> Tasks on Node <%s> for Task <%s/%s> costs: %v.",
+				len(victims), n.Name, task.Namespace, task.Name, cost)
+
+			if bestCost == nil || cost.Less(bestCost) {
+				bestNode, bestVictims, bestCost = n, victims, cost
+			}
+		}
+
+		assigned := false
+		if bestNode != nil {
+			// The task is pipelined on the resources releasing on node, including the
+			// ones released by the victims evicted before.
+			reclaimed := bestNode.Releasing.Clone()
 
 			// Reclaim victims for tasks.
-			for _, reclaimee := range victims {
+			for _, reclaimee := range bestVictims {
 				glog.Errorf("Try to reclaim Task <%s/%s> for Tasks <%s/%s>",
 					reclaimee.Namespace, reclaimee.Name, task.Namespace, task.Name)
-				if err := ssn.Evict(reclaimee, "reclaim"); err != nil {
+				reason := fmt.Sprintf("Reclaimed by Task <%s/%s>", task.Namespace, task.Name)
+				if err := ssn.Evict(reclaimee, reason); err != nil {
 					glog.Errorf("Failed to reclaim Task <%s/%s> for Tasks <%s/%s>: %v",
 						reclaimee.Namespace, reclaimee.Name, task.Namespace, task.Name, err)
 					continue
 				}
 				reclaimed.Add(reclaimee.Resreq)
-				// If reclaimed enough resources, break loop to avoid Sub panic.
-				if resreq.LessEqual(reclaimed) {
-					break
-				}
 			}
 
 			glog.V(3).Infof("Reclaimed <%v> for task <%s/%s> requested <%v>.",
 				reclaimed, task.Namespace, task.Name, task.InitResreq)
 
+			// The eviction of victims may fail, e.g. the victim was deleted after the
+			// snapshot; the task is not pipelined onto the resources not released.
 			if task.InitResreq.LessEqual(reclaimed) {
-				if err := ssn.Pipeline(task, n.Name); err != nil {
+				if err := ssn.Pipeline(task, bestNode.Name); err != nil {
 					glog.Errorf("Failed to pipeline Task <%s/%s> on Node <%s>",
-						task.Namespace, task.Name, n.Name)
+						task.Namespace, task.Name, bestNode.Name)
 				}
 
 				// Ignore error of pipeline, will be corrected in next scheduling loop.
 				assigned = true
-
-				break
 			}
 		}
 
@@ -109,7 +109,7 @@ func TestReclaim(t *testing.T) {
 		}
 		evictor := &util.FakeEvictor{
 			Evicts:  make([]string, 0),
-			Channel: make(chan string),
+			Channel: make(chan string, 10),
 		}
 		schedulerCache := &cache.SchedulerCache{
 			Nodes:         make(map[string]*api.NodeInfo),
@@ -169,3 +169,72 @@ func TestReclaim(t *testing.T) {
 		}
 	}
 }
+
+func TestReclaimDeletedVictims(t *testing.T) {
+	framework.RegisterPluginBuilder("conformance", conformance.New)
+	framework.RegisterPluginBuilder("gang", gang.New)
+	defer framework.CleanupPluginBuilders()
+
+	evictor := &util.FakeEvictor{
+		Evicts:  make([]string, 0),
+		Channel: make(chan string),
+	}
+	schedulerCache := cache.NewOfflineCache("kube-batch", "q1")
+	schedulerCache.Evictor = evictor
+	schedulerCache.StatusUpdater = &util.FakeStatusUpdater{}
+	schedulerCache.VolumeBinder = &util.FakeVolumeBinder{}
+	schedulerCache.Recorder = record.NewFakeRecorder(100)
+
+	schedulerCache.AddNode(util.BuildNode("n1", util.BuildResourceList("2", "2Gi"), make(map[string]string)))
+	for _, q := range []string{"q1", "q2"} {
+		schedulerCache.AddQueue(&kbv1.Queue{
+			ObjectMeta: metav1.ObjectMeta{Name: q},
+			Spec:       kbv1.QueueSpec{Weight: 1},
+		})
+	}
+	for _, pg := range []struct{ name, queue string }{{"pg1", "q1"}, {"pg2", "q2"}} {
+		schedulerCache.AddPodGroup(&kbv1.PodGroup{
+			ObjectMeta: metav1.ObjectMeta{Name: pg.name, Namespace: "c1"},
+			Spec:       kbv1.PodGroupSpec{Queue: pg.queue},
+		})
+	}
+	victims := []*v1.Pod{
+		util.BuildPod("c1", "preemptee1", "n1", v1.PodRunning, util.BuildResourceList("1", "1G"), "pg1", make(map[string]string), make(map[string]string)),
+		util.BuildPod("c1", "preemptee2", "n1", v1.PodRunning, util.BuildResourceList("1", "1G"), "pg1", make(map[string]string), make(map[string]string)),
+	}
+	for _, pod := range victims {
+		schedulerCache.AddPod(pod)
+	}
+	schedulerCache.AddPod(util.BuildPod("c1", "preemptor1", "", v1.PodPending, util.BuildResourceList("1", "1G"), "pg2", make(map[string]string), make(map[string]string)))
+
+	trueValue := true
+	ssn := framework.OpenSession(schedulerCache, []conf.Tier{
+		{
+			Plugins: []conf.PluginOption{
+				{
+					Name:               "conformance",
+					EnabledReclaimable: &trueValue,
+				},
+				{
+					Name:               "gang",
+					EnabledReclaimable: &trueValue,
+				},
+			},
+		},
+	})
+	defer framework.CloseSession(ssn)
+
+	// The victims are deleted after the snapshot, so they can not be evicted.
+	for _, pod := range victims {
+		schedulerCache.DeletePod(pod)
+	}
+
+	New().Execute(ssn)
+
+	if len(evictor.Evicts) != 0 {
+		t.Errorf("expected no eviction, got %v", evictor.Evicts)
+	}
+	if task := ssn.Jobs["c1/pg2"].Tasks["c1-preemptor1"]; task.Status != api.Pending {
+		t.Errorf("expected task <c1/preemptor1> %v, got %v", api.Pending, task.Status)
+	}
+}
@@ -0,0 +1,119 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reserve
+
+import (
+	"time"
+
+	"github.com/golang/glog"
+
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/api"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/framework"
+)
+
+const (
+	// LockTimeout is the key of the time the nodes are locked for a starving job,
+	// e.g. "30m"
+	LockTimeout = "reserve.lockTimeout"
+
+	defaultLockTimeout = 30 * time.Minute
+)
+
+func init() {
+	framework.RegisterActionArguments("reserve", LockTimeout)
+}
+
+type reserveAction struct {
+	// timeout is the time the nodes are locked for a starving job.
+	timeout time.Duration
+	// lock is the nodes locked for the starving job across sessions.
+	lock *framework.Reservation
+	// cooldown is the jobs whose lock expired, and the time until which they are not
+	// locked again, so a job which never fits does not hold the nodes forever.
+	cooldown map[api.JobID]time.Time
+}
+
+func New() *reserveAction {
+	return &reserveAction{
+		timeout:  defaultLockTimeout,
+		cooldown: map[api.JobID]time.Time{},
+	}
+}
+
+func (ra *reserveAction) Name() string {
+	return "reserve"
+}
+
+// Initialize sets the lock timeout by arguments; the nodes locked already are kept
+// locked until their lock expires.
+func (ra *reserveAction) Initialize(arguments framework.Arguments) {
+	ra.timeout = defaultLockTimeout
+	arguments.GetDuration(&ra.timeout, LockTimeout)
+}
+
+// Execute locks the nodes needed by the starving job of the highest order, and keeps
+// them locked in the following sessions until the job is ready or the lock expires;
+// the locked nodes are set as the reservation of session for the other actions.
+func (ra *reserveAction) Execute(ssn *framework.Session) {
+	glog.V(3).Infof("Enter Reserve ...")
+	defer glog.V(3).Infof("Leaving Reserve ...")
+
+	now := time.Now()
+	if lock := ra.lock; lock != nil {
+		if job, found := ssn.Jobs[lock.Job]; !found {
+			glog.V(3).Infof("Job <%s> is not found, unlock its nodes.", lock.Job)
+			ra.lock = nil
+		} else if ssn.JobReady(job) {
+			glog.V(3).Infof("Job <%s/%s> is ready, unlock its nodes.", job.Namespace, job.Name)
+			ra.lock = nil
+		} else if !now.Before(lock.Until) {
+			glog.V(3).Infof("The lock of Job <%s/%s> expired at %v, unlock its nodes.",
+				job.Namespace, job.Name, lock.Until)
+			ra.cooldown[job.UID] = now.Add(ra.timeout)
+			ra.lock = nil
+		} else if start, _, found := ssn.ReservedStart(job); found && start.After(now) {
+			// The expected start of job changes as the tasks on the nodes finish.
+			lock.Start = start
+		} else {
+			lock.Start = time.Time{}
+		}
+	}
+
+	excluded := map[api.JobID]bool{}
+	for job, until := range ra.cooldown {
+		if _, found := ssn.Jobs[job]; !found || !now.Before(until) {
+			delete(ra.cooldown, job)
+			continue
+		}
+		excluded[job] = true
+	}
+
+	// After the lock expires, the starving job of the highest order other than the
+	// ones cooling down is locked, with the nodes it needs then.
+	if ra.lock == nil {
+		if lock := ssn.Reserve(excluded); lock != nil {
+			lock.Until = now.Add(ra.timeout)
+			ra.lock = lock
+			glog.V(3).Infof("Locked <%d> nodes for Job <%s> until %v.",
+				len(lock.Nodes), lock.Job, lock.Until)
+		}
+	}
+
+	ssn.Reservation = ra.lock
+}
+
+func (ra *reserveAction) UnInitialize() {}
@@ -0,0 +1,261 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reserve
+
+import (
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	kbv1 "github.com/kubernetes-sigs/kube-batch/pkg/apis/scheduling/v1alpha1"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/actions/allocate"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/api"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/cache"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/conf"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/framework"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/plugins/aging"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/plugins/gang"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/util"
+)
+
+// runtimePlugin gives the expected runtime of the tasks of c1/pg3.
+type runtimePlugin struct {
+	runtime time.Duration
+}
+
+func (rp *runtimePlugin) Name() string {
+	return "runtime"
+}
+
+func (rp *runtimePlugin) OnSessionOpen(ssn *framework.Session) {
+	ssn.AddTaskRuntimeFn(rp.Name(), func(task *api.TaskInfo) (time.Duration, bool) {
+		if task.Job != "c1/pg3" || rp.runtime == 0 {
+			return 0, false
+		}
+		return rp.runtime, true
+	})
+}
+
+func (rp *runtimePlugin) OnSessionClose(ssn *framework.Session) {}
+
+// buildCache builds a cache with the node n1 used by pg2 and the idle node n2, the
+// gang pg1 which has been pending for two hours, and the small job pg3.
+func buildCache() *cache.SchedulerCache {
+	schedulerCache := cache.NewOfflineCache("kube-batch", "q1")
+	schedulerCache.Evictor = &util.FakeEvictor{}
+	schedulerCache.StatusUpdater = &util.FakeStatusUpdater{}
+	schedulerCache.VolumeBinder = &util.FakeVolumeBinder{}
+	schedulerCache.Binder = &util.FakeBinder{
+		Binds:   map[string]string{},
+		Channel: make(chan string, 4),
+	}
+	schedulerCache.Recorder = record.NewFakeRecorder(100)
+
+	for _, name := range []string{"n1", "n2"} {
+		schedulerCache.AddNode(util.BuildNode(name, util.BuildResourceList("2", "2G"), make(map[string]string)))
+	}
+	schedulerCache.AddQueue(&kbv1.Queue{
+		ObjectMeta: metav1.ObjectMeta{Name: "q1"},
+		Spec:       kbv1.QueueSpec{Weight: 1},
+	})
+
+	now := time.Now()
+	for _, pg := range []struct {
+		name      string
+		minMember int32
+		created   time.Time
+	}{
+		{"pg1", 2, now.Add(-2 * time.Hour)},
+		{"pg2", 1, now},
+		{"pg3", 1, now},
+	} {
+		schedulerCache.AddPodGroup(&kbv1.PodGroup{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              pg.name,
+				Namespace:         "c1",
+				CreationTimestamp: metav1.NewTime(pg.created),
+			},
+			Spec:   kbv1.PodGroupSpec{Queue: "q1", MinMember: pg.minMember},
+			Status: kbv1.PodGroupStatus{Phase: kbv1.PodGroupInqueue},
+		})
+	}
+
+	for _, p := range []struct{ name, node, cpu, pg string }{
+		{"p1", "", "2", "pg1"},
+		{"p2", "", "2", "pg1"},
+		{"p3", "n1", "2", "pg2"},
+		{"p4", "", "1", "pg3"},
+	} {
+		phase := v1.PodPending
+		if len(p.node) != 0 {
+			phase = v1.PodRunning
+		}
+		schedulerCache.AddPod(util.BuildPod("c1", p.name, p.node, phase, util.BuildResourceList(p.cpu, "1G"),
+			p.pg, make(map[string]string), make(map[string]string)))
+	}
+
+	return schedulerCache
+}
+
+func openSession(agingArguments framework.Arguments) *framework.Session {
+	trueValue := true
+	return framework.OpenSession(buildCache(), []conf.Tier{
+		{
+			Plugins: []conf.PluginOption{
+				{
+					Name:               "aging",
+					EnabledJobOrder:    &trueValue,
+					EnabledJobStarving: &trueValue,
+					Arguments:          agingArguments,
+				},
+				{
+					Name:                "gang",
+					EnabledJobReady:     &trueValue,
+					EnabledJobPipelined: &trueValue,
+				},
+				{
+					Name: "runtime",
+				},
+			},
+		},
+	})
+}
+
+func TestReserve(t *testing.T) {
+	framework.RegisterPluginBuilder("aging", aging.New)
+	framework.RegisterPluginBuilder("gang", gang.New)
+	defer framework.CleanupPluginBuilders()
+
+	tests := []struct {
+		name             string
+		agingArguments   framework.Arguments
+		reserveArguments framework.Arguments
+		runtime          time.Duration
+		locked           bool
+		// whether the task of small job pg3 is allocated
+		allocated bool
+	}{
+		{
+			name:           "no job is starving",
+			agingArguments: framework.Arguments{aging.StarvationThreshold: "3h"},
+			allocated:      true,
+		},
+		{
+			name:      "nodes are locked for starving job",
+			locked:    true,
+			allocated: false,
+		},
+		{
+			name:      "task finishes before the lock expires",
+			runtime:   10 * time.Minute,
+			locked:    true,
+			allocated: true,
+		},
+		{
+			name:             "task does not finish before the lock expires",
+			reserveArguments: framework.Arguments{LockTimeout: "5m"},
+			runtime:          10 * time.Minute,
+			locked:           true,
+			allocated:        false,
+		},
+	}
+
+	for i, test := range tests {
+		runtime := test.runtime
+		framework.RegisterPluginBuilder("runtime", func(framework.Arguments) framework.Plugin {
+			return &runtimePlugin{runtime: runtime}
+		})
+
+		ssn := openSession(test.agingArguments)
+
+		ra := New()
+		ra.Initialize(test.reserveArguments)
+		ra.Execute(ssn)
+		if locked := ssn.Reservation != nil; locked != test.locked {
+			t.Errorf("case %d (%s): expected nodes locked %t, got reservation %v",
+				i, test.name, test.locked, ssn.Reservation)
+		}
+
+		allocate.New().Execute(ssn)
+		if allocated := len(ssn.Jobs["c1/pg3"].TaskStatusIndex[api.Pending]) == 0; allocated != test.allocated {
+			t.Errorf("case %d (%s): expected task <c1/p4> allocated %t, got %t",
+				i, test.name, test.allocated, allocated)
+		}
+
+		framework.CloseSession(ssn)
+	}
+}
+
+func TestReserveLock(t *testing.T) {
+	framework.RegisterPluginBuilder("aging", aging.New)
+	framework.RegisterPluginBuilder("gang", gang.New)
+	framework.RegisterPluginBuilder("runtime", func(framework.Arguments) framework.Plugin {
+		return &runtimePlugin{}
+	})
+	defer framework.CleanupPluginBuilders()
+
+	tests := []struct {
+		name             string
+		reserveArguments framework.Arguments
+		// whether the lock of first session is kept in second session
+		kept bool
+	}{
+		{
+			name: "lock is kept across sessions",
+			kept: true,
+		},
+		{
+			name:             "lock expires",
+			reserveArguments: framework.Arguments{LockTimeout: "1ns"},
+			kept:             false,
+		},
+	}
+
+	for i, test := range tests {
+		ra := New()
+		ra.Initialize(test.reserveArguments)
+
+		ssn := openSession(nil)
+		ra.Execute(ssn)
+		first := ssn.Reservation
+		framework.CloseSession(ssn)
+
+		// The expired job is not locked again at once, so its nodes are released to
+		// the other jobs.
+		ssn = openSession(nil)
+		ra.Execute(ssn)
+		second := ssn.Reservation
+		allocate.New().Execute(ssn)
+		allocated := len(ssn.Jobs["c1/pg3"].TaskStatusIndex[api.Pending]) == 0
+		framework.CloseSession(ssn)
+
+		if first == nil {
+			t.Errorf("case %d (%s): expected nodes locked in first session, got nil", i, test.name)
+			continue
+		}
+		if kept := second != nil && first.Until.Equal(second.Until); kept != test.kept {
+			t.Errorf("case %d (%s): expected lock kept %t, got %v and %v", i, test.name, test.kept, first, second)
+		}
+		if allocated != !test.kept {
+			t.Errorf("case %d (%s): expected task <c1/p4> allocated %t, got %t",
+				i, test.name, !test.kept, allocated)
+		}
+	}
+}
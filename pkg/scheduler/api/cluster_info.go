@@ -16,13 +16,18 @@ limitations under the License.
 
 package api
 
-import "fmt"
+import (
+	"fmt"
+
+	policyv1 "k8s.io/api/policy/v1beta1"
+)
 
 // ClusterInfo is a snapshot of cluster by cache.
 type ClusterInfo struct {
 	Jobs   map[JobID]*JobInfo
 	Nodes  map[string]*NodeInfo
 	Queues map[QueueID]*QueueInfo
+	PDBs   []*policyv1.PodDisruptionBudget
 }
 
 func (ci ClusterInfo) String() string {
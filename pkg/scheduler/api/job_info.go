@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+	"time"
 
 	v1 "k8s.io/api/core/v1"
 	policyv1 "k8s.io/api/policy/v1beta1"
@@ -196,6 +197,26 @@ func (ji *JobInfo) SetPodGroup(pg *v1alpha1.PodGroup) {
 	ji.PodGroup = pg
 }
 
+// ExpectedRuntime returns the expected runtime of the tasks of job declared by the
+// annotation of its PodGroup; it returns false if not declared or invalid.
+func (ji *JobInfo) ExpectedRuntime() (time.Duration, bool) {
+	if ji.PodGroup == nil {
+		return 0, false
+	}
+
+	value, found := ji.PodGroup.Annotations[v1alpha1.ExpectedRuntimeAnnotationKey]
+	if !found {
+		return 0, false
+	}
+
+	runtime, err := time.ParseDuration(value)
+	if err != nil || runtime <= 0 {
+		return 0, false
+	}
+
+	return runtime, true
+}
+
 // SetPDB sets PDB to a job
 func (ji *JobInfo) SetPDB(pdb *policyv1.PodDisruptionBudget) {
 	ji.Name = pdb.Name
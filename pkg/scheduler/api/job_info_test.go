@@ -19,9 +19,12 @@ package api
 import (
 	"reflect"
 	"testing"
+	"time"
 
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kubernetes-sigs/kube-batch/pkg/apis/scheduling/v1alpha1"
 )
 
 func jobInfoEqual(l, r *JobInfo) bool {
@@ -201,3 +204,50 @@ func TestDeleteTaskInfo(t *testing.T) {
 		}
 	}
 }
+
+func TestJobInfoExpectedRuntime(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		runtime     time.Duration
+		found       bool
+	}{
+		{
+			name:        "declared runtime",
+			annotations: map[string]string{v1alpha1.ExpectedRuntimeAnnotationKey: "30m"},
+			runtime:     30 * time.Minute,
+			found:       true,
+		},
+		{
+			name:  "runtime not declared",
+			found: false,
+		},
+		{
+			name:        "invalid runtime",
+			annotations: map[string]string{v1alpha1.ExpectedRuntimeAnnotationKey: "soon"},
+			found:       false,
+		},
+		{
+			name:        "negative runtime",
+			annotations: map[string]string{v1alpha1.ExpectedRuntimeAnnotationKey: "-1m"},
+			found:       false,
+		},
+	}
+
+	for i, test := range tests {
+		job := NewJobInfo("uid")
+		job.SetPodGroup(&v1alpha1.PodGroup{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "pg1",
+				Namespace:   "c1",
+				Annotations: test.annotations,
+			},
+		})
+
+		runtime, found := job.ExpectedRuntime()
+		if runtime != test.runtime || found != test.found {
+			t.Errorf("case %d (%s): expected runtime %v (%t), got %v (%t)",
+				i, test.name, test.runtime, test.found, runtime, found)
+		}
+	}
+}
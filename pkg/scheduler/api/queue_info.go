@@ -31,6 +31,16 @@ type QueueInfo struct {
 	Name string
 
 	Weight int32
+	// Parent is the ID of parent queue, empty for top-level queue.
+	Parent QueueID
+
+	// State is the current state of queue, which is reported by cache.
+	State arbcorev1.QueueState
+
+	// Deserved and Share are the deserved resource and share of queue in
+	// the session, which are set by plugins, e.g. proportion.
+	Deserved *Resource
+	Share    float64
 
 	Queue *arbcorev1.Queue
 }
@@ -42,6 +52,9 @@ func NewQueueInfo(queue *arbcorev1.Queue) *QueueInfo {
 		Name: queue.Name,
 
 		Weight: queue.Spec.Weight,
+		Parent: QueueID(queue.Spec.Parent),
+
+		State: queue.Status.State,
 
 		Queue: queue,
 	}
@@ -53,6 +66,11 @@ func (q *QueueInfo) Clone() *QueueInfo {
 		UID:    q.UID,
 		Name:   q.Name,
 		Weight: q.Weight,
+		Parent: q.Parent,
+		State:  q.State,
 		Queue:  q.Queue,
+
+		Deserved: q.Deserved,
+		Share:    q.Share,
 	}
 }
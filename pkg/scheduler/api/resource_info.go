@@ -21,6 +21,7 @@ import (
 	"math"
 
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	v1helper "k8s.io/kubernetes/pkg/apis/core/v1/helper"
 
 	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/util/assert"
@@ -92,6 +93,20 @@ func NewResource(rl v1.ResourceList) *Resource {
 	return r
 }
 
+// ResourceList converts the resource object back to resource list
+func (r *Resource) ResourceList() v1.ResourceList {
+	rl := v1.ResourceList{
+		v1.ResourceCPU:    *resource.NewMilliQuantity(int64(r.MilliCPU), resource.DecimalSI),
+		v1.ResourceMemory: *resource.NewQuantity(int64(r.Memory), resource.BinarySI),
+	}
+
+	for rName, rQuant := range r.ScalarResources {
+		rl[rName] = *resource.NewMilliQuantity(int64(rQuant), resource.DecimalSI)
+	}
+
+	return rl
+}
+
 // IsEmpty returns bool after checking any of resource is less than min possible value
 func (r *Resource) IsEmpty() bool {
 	if !(r.MilliCPU < minMilliCPU && r.Memory < minMemory) {
@@ -17,6 +17,8 @@ limitations under the License.
 package api
 
 import (
+	"time"
+
 	schedulerapi "k8s.io/kubernetes/pkg/scheduler/api"
 )
 
@@ -133,6 +135,10 @@ type PredicateFn func(*TaskInfo, *NodeInfo) error
 // EvictableFn is the func declaration used to evict tasks.
 type EvictableFn func(*TaskInfo, []*TaskInfo) []*TaskInfo
 
+// RuntimeFn is the func declaration used to get the expected runtime of task; it
+// returns false if the runtime is unknown.
+type RuntimeFn func(*TaskInfo) (time.Duration, bool)
+
 // NodeOrderFn is the func declaration used to get priority score for a node for a particular task.
 type NodeOrderFn func(*TaskInfo, *NodeInfo) (float64, error)
 
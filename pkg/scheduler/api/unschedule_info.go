@@ -53,6 +53,15 @@ func (f *FitErrors) SetNodeError(nodeName string, err error) {
 	f.nodes[nodeName] = fe
 }
 
+// NodeReasons returns the reasons why the task could not fit each node
+func (f *FitErrors) NodeReasons() map[string][]string {
+	reasons := make(map[string][]string, len(f.nodes))
+	for name, node := range f.nodes {
+		reasons[name] = node.Reasons
+	}
+	return reasons
+}
+
 // Error returns the final error message
 func (f *FitErrors) Error() string {
 	reasons := make(map[string]int)
@@ -24,7 +24,9 @@ import (
 	"github.com/golang/glog"
 
 	v1 "k8s.io/api/core/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
 	"k8s.io/api/scheduling/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
@@ -54,6 +56,10 @@ import (
 	kbapi "github.com/kubernetes-sigs/kube-batch/pkg/scheduler/api"
 )
 
+// evictionRejectedPeriod is how long a task is not evicted again after its
+// eviction was rejected, e.g. by PodDisruptionBudget.
+const evictionRejectedPeriod = 30 * time.Second
+
 func init() {
 	schemeBuilder := runtime.SchemeBuilder{
 		v1.AddToScheme,
@@ -67,6 +73,23 @@ func New(config *rest.Config, schedulerName string, defaultQueue string) Cache {
 	return newSchedulerCache(config, schedulerName, defaultQueue)
 }
 
+// NewOfflineCache returns a SchedulerCache which is not connected to the API server,
+// e.g. for simulation; the objects are added by its event handlers, e.g. AddPod, and
+// the Binder, Evictor, StatusUpdater, VolumeBinder and Recorder have to be set by caller.
+func NewOfflineCache(schedulerName string, defaultQueue string) *SchedulerCache {
+	return &SchedulerCache{
+		Jobs:            make(map[kbapi.JobID]*kbapi.JobInfo),
+		Nodes:           make(map[string]*kbapi.NodeInfo),
+		Queues:          make(map[kbapi.QueueID]*kbapi.QueueInfo),
+		PriorityClasses: make(map[string]*v1beta1.PriorityClass),
+		PDBs:            make(map[string]*policyv1beta1.PodDisruptionBudget),
+		errTasks:        workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter()),
+		deletedJobs:     workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter()),
+		defaultQueue:    defaultQueue,
+		schedulerName:   schedulerName,
+	}
+}
+
 //SchedulerCache cache for the kube batch
 type SchedulerCache struct {
 	sync.Mutex
@@ -102,6 +125,13 @@ type SchedulerCache struct {
 	PriorityClasses      map[string]*v1beta1.PriorityClass
 	defaultPriorityClass *v1beta1.PriorityClass
 	defaultPriority      int32
+	// PDBs are the PodDisruptionBudgets keyed by namespace/name, which limit the
+	// evictions of tasks.
+	PDBs map[string]*policyv1beta1.PodDisruptionBudget
+
+	// evictionRejected is the expiration of rejected evictions of tasks.
+	evictionLock     sync.Mutex
+	evictionRejected map[kbapi.TaskID]time.Time
 
 	errTasks    workqueue.RateLimitingInterface
 	deletedJobs workqueue.RateLimitingInterface
@@ -130,11 +160,21 @@ type defaultEvictor struct {
 	kubeclient *kubernetes.Clientset
 }
 
-//Evict will send delete pod request to api server
-func (de *defaultEvictor) Evict(p *v1.Pod) error {
+//Evict will send eviction request of pod to api server, which is rejected with
+//429 (TooManyRequests) if it violates PodDisruptionBudget
+func (de *defaultEvictor) Evict(p *v1.Pod, gracePeriodSeconds *int64) error {
 	glog.V(3).Infof("Evicting pod %v/%v", p.Namespace, p.Name)
 
-	if err := de.kubeclient.CoreV1().Pods(p.Namespace).Delete(p.Name, nil); err != nil {
+	eviction := &policyv1beta1.Eviction{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      p.Name,
+			Namespace: p.Namespace,
+		},
+	}
+	if gracePeriodSeconds != nil {
+		eviction.DeleteOptions = &metav1.DeleteOptions{GracePeriodSeconds: gracePeriodSeconds}
+	}
+	if err := de.kubeclient.PolicyV1beta1().Evictions(p.Namespace).Evict(eviction); err != nil {
 		glog.Errorf("Failed to evict pod <%v/%v>: %#v", p.Namespace, p.Name, err)
 		return err
 	}
@@ -186,6 +226,11 @@ func (su *defaultStatusUpdater) UpdatePodGroup(pg *v1alpha1.PodGroup) (*v1alpha1
 	return su.kbclient.SchedulingV1alpha1().PodGroups(pg.Namespace).Update(pg)
 }
 
+// UpdateQueueStatus will update the status of queue
+func (su *defaultStatusUpdater) UpdateQueueStatus(queue *v1alpha1.Queue) (*v1alpha1.Queue, error) {
+	return su.kbclient.SchedulingV1alpha1().Queues().UpdateStatus(queue)
+}
+
 type defaultVolumeBinder struct {
 	volumeBinder *volumebinder.VolumeBinder
 }
@@ -208,6 +253,15 @@ func (dvb *defaultVolumeBinder) BindVolumes(task *api.TaskInfo) error {
 	return dvb.volumeBinder.Binder.BindPodVolumes(task.Pod)
 }
 
+// RevertVolumes removes the volume bindings assumed for the task, so they are
+// not bound if the task is not allocated to the host any more.
+func (dvb *defaultVolumeBinder) RevertVolumes(task *api.TaskInfo) error {
+	dvb.volumeBinder.Binder.GetBindingsCache().DeleteBindings(task.Pod)
+	task.VolumeReady = false
+
+	return nil
+}
+
 func newSchedulerCache(config *rest.Config, schedulerName string, defaultQueue string) *SchedulerCache {
 	kubeClient, err := kubernetes.NewForConfig(config)
 	if err != nil {
@@ -227,6 +281,7 @@ func newSchedulerCache(config *rest.Config, schedulerName string, defaultQueue s
 		Nodes:           make(map[string]*kbapi.NodeInfo),
 		Queues:          make(map[kbapi.QueueID]*kbapi.QueueInfo),
 		PriorityClasses: make(map[string]*v1beta1.PriorityClass),
+		PDBs:            make(map[string]*policyv1beta1.PodDisruptionBudget),
 		errTasks:        workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter()),
 		deletedJobs:     workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter()),
 		kubeclient:      kubeClient,
@@ -358,6 +413,9 @@ func (sc *SchedulerCache) Run(stopCh <-chan struct{}) {
 
 	// Cleanup jobs.
 	go wait.Until(sc.processCleanupJob, 0, stopCh)
+
+	// Cleanup expired rejections of eviction.
+	go wait.Until(sc.cleanupEvictionRejected, evictionRejectedPeriod, stopCh)
 }
 
 // WaitForCacheSync sync the cache with the api server
@@ -400,25 +458,61 @@ func (sc *SchedulerCache) findJobAndTask(taskInfo *kbapi.TaskInfo) (*kbapi.JobIn
 	return job, task, nil
 }
 
-// Evict will evict the pod
+// Evict will evict the pod; the eviction is sent to apiserver before returning, so
+// the caller knows whether the task is evicted, e.g. it is rejected by
+// PodDisruptionBudget.
 func (sc *SchedulerCache) Evict(taskInfo *kbapi.TaskInfo, reason string) error {
 	sc.Mutex.Lock()
-	defer sc.Mutex.Unlock()
-
 	job, task, err := sc.findJobAndTask(taskInfo)
-
 	if err != nil {
+		sc.Mutex.Unlock()
 		return err
 	}
+	if _, found := sc.Nodes[task.NodeName]; !found {
+		sc.Mutex.Unlock()
+		return fmt.Errorf("failed to evict Task %v from host %v, host does not exist",
+			task.UID, task.NodeName)
+	}
 
+	p := task.Pod
+	gracePeriod := sc.evictionGracePeriod(job)
+
+	// The PodGroup is notified before its pods are evicted, so the controllers
+	// can save the state of job within the grace period; it's notified once for
+	// the tasks of a gang evicted for the same reason.
+	var pg *v1alpha1.PodGroup
+	if !shadowPodGroup(job.PodGroup) && !preemptedBy(job.PodGroup, reason) {
+		pg = job.PodGroup.DeepCopy()
+	}
+	sc.Mutex.Unlock()
+
+	// The apiserver is called without holding the lock of cache.
+	if pg != nil {
+		sc.notifyPreempted(pg, reason)
+	}
+	if err := sc.Evictor.Evict(p, gracePeriod); err != nil {
+		// The eviction is rejected by PodDisruptionBudget, the task is not
+		// evicted again until the rejection expires.
+		if apierrors.IsTooManyRequests(err) {
+			sc.rejectEviction(task)
+		}
+		return err
+	}
+
+	sc.Mutex.Lock()
+	defer sc.Mutex.Unlock()
+
+	// The task may be deleted once evicted, which is released already.
+	job, task, err = sc.findJobAndTask(taskInfo)
+	if err != nil {
+		return nil
+	}
 	node, found := sc.Nodes[task.NodeName]
 	if !found {
-		return fmt.Errorf("failed to bind Task %v to host %v, host does not exist",
-			task.UID, task.NodeName)
+		return nil
 	}
 
-	err = job.UpdateTaskStatus(task, kbapi.Releasing)
-	if err != nil {
+	if err := job.UpdateTaskStatus(task, kbapi.Releasing); err != nil {
 		return err
 	}
 
@@ -427,15 +521,6 @@ func (sc *SchedulerCache) Evict(taskInfo *kbapi.TaskInfo, reason string) error {
 		return err
 	}
 
-	p := task.Pod
-
-	go func() {
-		err := sc.Evictor.Evict(p)
-		if err != nil {
-			sc.resyncTask(task)
-		}
-	}()
-
 	if !shadowPodGroup(job.PodGroup) {
 		sc.Recorder.Eventf(job.PodGroup, v1.EventTypeNormal, "Evict", reason)
 	}
@@ -443,6 +528,114 @@ func (sc *SchedulerCache) Evict(taskInfo *kbapi.TaskInfo, reason string) error {
 	return nil
 }
 
+// evictionGracePeriod returns the grace period of evicting the tasks of job, which
+// is given by its PodGroup or queue.
+func (sc *SchedulerCache) evictionGracePeriod(job *kbapi.JobInfo) *int64 {
+	if job.PodGroup != nil && job.PodGroup.Spec.EvictionGracePeriodSeconds != nil {
+		return job.PodGroup.Spec.EvictionGracePeriodSeconds
+	}
+	if queue, found := sc.Queues[job.Queue]; found && queue.Queue != nil {
+		return queue.Queue.Spec.EvictionGracePeriodSeconds
+	}
+	return nil
+}
+
+// preemptedBy returns whether the Preempted condition of PodGroup is set with the
+// reason of eviction already.
+func preemptedBy(pg *v1alpha1.PodGroup, reason string) bool {
+	for _, c := range pg.Status.Conditions {
+		if c.Type == v1alpha1.PodGroupPreemptedType {
+			return c.Status == v1.ConditionTrue && c.Message == reason
+		}
+	}
+	return false
+}
+
+// notifyPreempted sets the Preempted condition of PodGroup with the reason of
+// eviction, which names the preemptor.
+func (sc *SchedulerCache) notifyPreempted(pg *v1alpha1.PodGroup, reason string) {
+	condition := v1alpha1.PodGroupCondition{
+		Type:               v1alpha1.PodGroupPreemptedType,
+		Status:             v1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             v1alpha1.PreemptedReason,
+		Message:            reason,
+	}
+
+	index := -1
+	for i, c := range pg.Status.Conditions {
+		if c.Type == condition.Type {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		pg.Status.Conditions = append(pg.Status.Conditions, condition)
+	} else {
+		pg.Status.Conditions[index] = condition
+	}
+
+	updated, err := sc.StatusUpdater.UpdatePodGroup(pg)
+	if err != nil {
+		glog.Errorf("Failed to set Preempted condition of PodGroup <%s/%s>: %v",
+			pg.Namespace, pg.Name, err)
+		return
+	}
+	if updated == nil {
+		return
+	}
+
+	sc.Mutex.Lock()
+	defer sc.Mutex.Unlock()
+
+	// The later updates of PodGroup are based on the updated one.
+	if job, found := sc.Jobs[getJobID(pg)]; found && job.PodGroup != nil &&
+		job.PodGroup.ResourceVersion == pg.ResourceVersion {
+		job.SetPodGroup(updated)
+	}
+}
+
+func (sc *SchedulerCache) rejectEviction(task *kbapi.TaskInfo) {
+	sc.evictionLock.Lock()
+	defer sc.evictionLock.Unlock()
+
+	if sc.evictionRejected == nil {
+		sc.evictionRejected = map[kbapi.TaskID]time.Time{}
+	}
+	sc.evictionRejected[task.UID] = time.Now().Add(evictionRejectedPeriod)
+}
+
+// cleanupEvictionRejected forgets the rejected evictions which expired, including
+// the ones of tasks which are deleted and never looked up again.
+func (sc *SchedulerCache) cleanupEvictionRejected() {
+	sc.evictionLock.Lock()
+	defer sc.evictionLock.Unlock()
+
+	now := time.Now()
+	for uid, expiration := range sc.evictionRejected {
+		if now.After(expiration) {
+			delete(sc.evictionRejected, uid)
+		}
+	}
+}
+
+// EvictionRejected returns whether the last eviction of task was rejected
+// with 429 (TooManyRequests) recently.
+func (sc *SchedulerCache) EvictionRejected(task *kbapi.TaskInfo) bool {
+	sc.evictionLock.Lock()
+	defer sc.evictionLock.Unlock()
+
+	expiration, found := sc.evictionRejected[task.UID]
+	if !found {
+		return false
+	}
+	if time.Now().After(expiration) {
+		delete(sc.evictionRejected, task.UID)
+		return false
+	}
+	return true
+}
+
 // Bind binds task to the target host.
 func (sc *SchedulerCache) Bind(taskInfo *kbapi.TaskInfo, hostname string) error {
 	sc.Mutex.Lock()
@@ -496,6 +689,11 @@ func (sc *SchedulerCache) BindVolumes(task *api.TaskInfo) error {
 	return sc.VolumeBinder.BindVolumes(task)
 }
 
+// RevertVolumes reverts the volumes allocated to the task
+func (sc *SchedulerCache) RevertVolumes(task *api.TaskInfo) error {
+	return sc.VolumeBinder.RevertVolumes(task)
+}
+
 // taskUnschedulable updates pod status of pending task
 func (sc *SchedulerCache) taskUnschedulable(task *api.TaskInfo, message string) error {
 	pod := task.Pod
@@ -603,6 +801,14 @@ func (sc *SchedulerCache) Snapshot() *kbapi.ClusterInfo {
 		snapshot.Queues[value.UID] = value.Clone()
 	}
 
+	for _, value := range sc.PDBs {
+		snapshot.PDBs = append(snapshot.PDBs, value.DeepCopy())
+	}
+
+	for _, value := range snapshot.Queues {
+		value.State = sc.queueState(value)
+	}
+
 	var cloneJobLock sync.Mutex
 	var wg sync.WaitGroup
 
@@ -653,6 +859,32 @@ func (sc *SchedulerCache) Snapshot() *kbapi.ClusterInfo {
 	return snapshot
 }
 
+// queueState returns the current state of queue: a Closed queue is Closing
+// until all the PodGroups admitted into it are finished.
+func (sc *SchedulerCache) queueState(queue *kbapi.QueueInfo) v1alpha1.QueueState {
+	if queue.Queue.Spec.State != v1alpha1.QueueStateClosed {
+		return v1alpha1.QueueStateOpen
+	}
+
+	for _, job := range sc.Jobs {
+		if job.Queue != queue.UID {
+			continue
+		}
+
+		if job.PodGroup != nil && job.PodGroup.Status.Phase != v1alpha1.PodGroupPending {
+			return v1alpha1.QueueStateClosing
+		}
+
+		for status := range job.TaskStatusIndex {
+			if api.AllocatedStatus(status) || status == api.Releasing {
+				return v1alpha1.QueueStateClosing
+			}
+		}
+	}
+
+	return v1alpha1.QueueStateClosed
+}
+
 // String returns information about the cache in a string format
 func (sc *SchedulerCache) String() string {
 	sc.Mutex.Lock()
@@ -734,3 +966,14 @@ func (sc *SchedulerCache) UpdateJobStatus(job *kbapi.JobInfo, updatePG bool) (*k
 
 	return job, nil
 }
+
+// UpdateQueueStatus updates the status of queue.
+func (sc *SchedulerCache) UpdateQueueStatus(queue *kbapi.QueueInfo) error {
+	if _, err := sc.StatusUpdater.UpdateQueueStatus(queue.Queue); err != nil {
+		return err
+	}
+
+	glog.V(4).Infof("Updated the status of Queue <%s>: %v.", queue.Name, queue.Queue.Status)
+
+	return nil
+}
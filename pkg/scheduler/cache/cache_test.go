@@ -19,13 +19,18 @@ package cache
 import (
 	"fmt"
 	"reflect"
+	"sync"
 	"testing"
+	"time"
 
 	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 
+	kbv1 "github.com/kubernetes-sigs/kube-batch/pkg/apis/scheduling/v1alpha1"
 	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/api"
 )
 
@@ -307,3 +312,304 @@ func TestGetOrCreateJob(t *testing.T) {
 		}
 	}
 }
+
+func TestSnapshotQueueState(t *testing.T) {
+	buildQueue := func(name string, state kbv1.QueueState) *kbv1.Queue {
+		return &kbv1.Queue{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name,
+			},
+			Spec: kbv1.QueueSpec{
+				Weight: 1,
+				State:  state,
+			},
+		}
+	}
+	buildPodGroup := func(name, queue string, phase kbv1.PodGroupPhase) *kbv1.PodGroup {
+		return &kbv1.PodGroup{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "c1",
+			},
+			Spec: kbv1.PodGroupSpec{
+				Queue: queue,
+			},
+			Status: kbv1.PodGroupStatus{
+				Phase: phase,
+			},
+		}
+	}
+
+	cache := &SchedulerCache{
+		Nodes:  make(map[string]*api.NodeInfo),
+		Jobs:   make(map[api.JobID]*api.JobInfo),
+		Queues: make(map[api.QueueID]*api.QueueInfo),
+	}
+
+	for _, q := range []*kbv1.Queue{
+		buildQueue("q1", ""),
+		buildQueue("q2", kbv1.QueueStateClosed),
+		buildQueue("q3", kbv1.QueueStateClosed),
+	} {
+		cache.AddQueue(q)
+	}
+
+	for _, pg := range []*kbv1.PodGroup{
+		buildPodGroup("pg1", "q1", kbv1.PodGroupPending),
+		buildPodGroup("pg2", "q2", kbv1.PodGroupRunning),
+		buildPodGroup("pg3", "q3", kbv1.PodGroupPending),
+	} {
+		cache.AddPodGroup(pg)
+	}
+
+	expected := map[api.QueueID]kbv1.QueueState{
+		"q1": kbv1.QueueStateOpen,
+		"q2": kbv1.QueueStateClosing,
+		"q3": kbv1.QueueStateClosed,
+	}
+
+	snapshot := cache.Snapshot()
+	for queue, state := range expected {
+		if got := snapshot.Queues[queue].State; got != state {
+			t.Errorf("expected state of queue <%s>: %s, got %s", queue, state, got)
+		}
+	}
+}
+
+// preemptionRecorder records the updates of PodGroup and the evictions in order.
+type preemptionRecorder struct {
+	sync.Mutex
+	actions      []string
+	gracePeriods map[string]*int64
+	conditions   map[string]kbv1.PodGroupCondition
+	evicted      chan string
+}
+
+func (pr *preemptionRecorder) Evict(p *v1.Pod, gracePeriodSeconds *int64) error {
+	pr.Lock()
+	defer pr.Unlock()
+
+	key := fmt.Sprintf("%s/%s", p.Namespace, p.Name)
+	pr.actions = append(pr.actions, "evict "+key)
+	pr.gracePeriods[key] = gracePeriodSeconds
+	pr.evicted <- key
+	return nil
+}
+
+func (pr *preemptionRecorder) UpdatePodCondition(pod *v1.Pod, podCondition *v1.PodCondition) (*v1.Pod, error) {
+	return pod, nil
+}
+
+func (pr *preemptionRecorder) UpdatePodGroup(pg *kbv1.PodGroup) (*kbv1.PodGroup, error) {
+	pr.Lock()
+	defer pr.Unlock()
+
+	key := fmt.Sprintf("%s/%s", pg.Namespace, pg.Name)
+	pr.actions = append(pr.actions, "update "+key)
+	for _, c := range pg.Status.Conditions {
+		if c.Type == kbv1.PodGroupPreemptedType {
+			pr.conditions[key] = c
+		}
+	}
+	return pg, nil
+}
+
+func (pr *preemptionRecorder) UpdateQueueStatus(queue *kbv1.Queue) (*kbv1.Queue, error) {
+	return queue, nil
+}
+
+func TestEvictGracefully(t *testing.T) {
+	int64Ptr := func(i int64) *int64 {
+		return &i
+	}
+
+	pr := &preemptionRecorder{
+		gracePeriods: map[string]*int64{},
+		conditions:   map[string]kbv1.PodGroupCondition{},
+		evicted:      make(chan string, 3),
+	}
+	sc := NewOfflineCache("kube-batch", "q1")
+	sc.Evictor = pr
+	sc.StatusUpdater = pr
+	sc.Recorder = record.NewFakeRecorder(10)
+
+	sc.AddNode(buildNode("n1", buildResourceList("4", "4G")))
+	sc.AddQueue(&kbv1.Queue{
+		ObjectMeta: metav1.ObjectMeta{Name: "q1"},
+		Spec:       kbv1.QueueSpec{Weight: 1, EvictionGracePeriodSeconds: int64Ptr(60)},
+	})
+	sc.AddQueue(&kbv1.Queue{
+		ObjectMeta: metav1.ObjectMeta{Name: "q2"},
+		Spec:       kbv1.QueueSpec{Weight: 1},
+	})
+	for _, pg := range []*kbv1.PodGroup{
+		// The grace period of PodGroup overrides the one of queue.
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "pg1", Namespace: "c1"},
+			Spec:       kbv1.PodGroupSpec{Queue: "q1", EvictionGracePeriodSeconds: int64Ptr(300)},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "pg2", Namespace: "c1"},
+			Spec:       kbv1.PodGroupSpec{Queue: "q1"},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "pg3", Namespace: "c1"},
+			Spec:       kbv1.PodGroupSpec{Queue: "q2"},
+		},
+	} {
+		sc.AddPodGroup(pg)
+	}
+
+	tasks := map[string]*api.TaskInfo{}
+	for _, p := range []struct{ name, pg string }{{"p1", "pg1"}, {"p2", "pg2"}, {"p3", "pg3"}} {
+		pod := buildPod("c1", p.name, "n1", v1.PodRunning, buildResourceList("1", "1G"),
+			[]metav1.OwnerReference{}, map[string]string{})
+		pod.Annotations = map[string]string{kbv1.GroupNameAnnotationKey: p.pg}
+		sc.AddPod(pod)
+		tasks[p.name] = api.NewTaskInfo(pod)
+	}
+
+	reason := "Preempted by Task <c1/preemptor>"
+	for _, name := range []string{"p1", "p2", "p3"} {
+		if err := sc.Evict(tasks[name], reason); err != nil {
+			t.Fatalf("failed to evict task %s: %v", name, err)
+		}
+	}
+	for i := 0; i < 3; i++ {
+		select {
+		case <-pr.evicted:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("expected 3 evictions, got %d", i)
+		}
+	}
+
+	pr.Lock()
+	defer pr.Unlock()
+
+	expectedGracePeriods := map[string]*int64{
+		"c1/p1": int64Ptr(300),
+		"c1/p2": int64Ptr(60),
+		"c1/p3": nil,
+	}
+	if !reflect.DeepEqual(expectedGracePeriods, pr.gracePeriods) {
+		t.Errorf("expected grace periods %v, got %v", expectedGracePeriods, pr.gracePeriods)
+	}
+
+	// The PodGroup of each victim is notified before the eviction is sent.
+	for _, p := range []struct{ name, pg string }{{"p1", "pg1"}, {"p2", "pg2"}, {"p3", "pg3"}} {
+		updated, evicted := -1, -1
+		for i, action := range pr.actions {
+			switch action {
+			case "update c1/" + p.pg:
+				updated = i
+			case "evict c1/" + p.name:
+				evicted = i
+			}
+		}
+		if updated < 0 || updated > evicted {
+			t.Errorf("expected PodGroup <c1/%s> to be updated before evicting <c1/%s>, got %v",
+				p.pg, p.name, pr.actions)
+		}
+
+		condition := pr.conditions["c1/"+p.pg]
+		if condition.Reason != kbv1.PreemptedReason || condition.Message != reason ||
+			condition.Status != v1.ConditionTrue {
+			t.Errorf("expected Preempted condition of PodGroup <c1/%s>, got %v", p.pg, condition)
+		}
+	}
+}
+
+func TestNotifyPreemptedOnce(t *testing.T) {
+	pr := &preemptionRecorder{
+		gracePeriods: map[string]*int64{},
+		conditions:   map[string]kbv1.PodGroupCondition{},
+		evicted:      make(chan string, 3),
+	}
+	sc := NewOfflineCache("kube-batch", "q1")
+	sc.Evictor = pr
+	sc.StatusUpdater = pr
+	sc.Recorder = record.NewFakeRecorder(10)
+
+	sc.AddNode(buildNode("n1", buildResourceList("4", "4G")))
+	sc.AddPodGroup(&kbv1.PodGroup{
+		ObjectMeta: metav1.ObjectMeta{Name: "pg1", Namespace: "c1"},
+		Spec:       kbv1.PodGroupSpec{Queue: "q1"},
+	})
+	tasks := map[string]*api.TaskInfo{}
+	for _, name := range []string{"p1", "p2", "p3"} {
+		pod := buildPod("c1", name, "n1", v1.PodRunning, buildResourceList("1", "1G"),
+			[]metav1.OwnerReference{}, map[string]string{})
+		pod.Annotations = map[string]string{kbv1.GroupNameAnnotationKey: "pg1"}
+		sc.AddPod(pod)
+		tasks[name] = api.NewTaskInfo(pod)
+	}
+
+	// The tasks of gang evicted for the same reason update the PodGroup once.
+	for _, e := range []struct{ name, reason string }{
+		{"p1", "Preempted as a gang by Job <c1/pg2>"},
+		{"p2", "Preempted as a gang by Job <c1/pg2>"},
+		{"p3", "Preempted by Task <c1/preemptor>"},
+	} {
+		if err := sc.Evict(tasks[e.name], e.reason); err != nil {
+			t.Fatalf("failed to evict task %s: %v", e.name, err)
+		}
+	}
+
+	pr.Lock()
+	defer pr.Unlock()
+
+	expected := []string{
+		"update c1/pg1", "evict c1/p1", "evict c1/p2",
+		"update c1/pg1", "evict c1/p3",
+	}
+	if !reflect.DeepEqual(expected, pr.actions) {
+		t.Errorf("expected actions %v, got %v", expected, pr.actions)
+	}
+}
+
+// rejectingEvictor rejects all evictions with 429, as API server does if the
+// eviction violates PodDisruptionBudget.
+type rejectingEvictor struct{}
+
+func (re *rejectingEvictor) Evict(p *v1.Pod, gracePeriodSeconds *int64) error {
+	return apierrors.NewTooManyRequests("Cannot evict pod as it would violate the pod's disruption budget.", 0)
+}
+
+func TestEvictionRejected(t *testing.T) {
+	sc := NewOfflineCache("kube-batch", "q1")
+	sc.Evictor = &rejectingEvictor{}
+	sc.StatusUpdater = &preemptionRecorder{conditions: map[string]kbv1.PodGroupCondition{}}
+	sc.Recorder = record.NewFakeRecorder(10)
+
+	sc.AddNode(buildNode("n1", buildResourceList("2", "2G")))
+	sc.AddPodGroup(&kbv1.PodGroup{
+		ObjectMeta: metav1.ObjectMeta{Name: "pg1", Namespace: "c1"},
+		Spec:       kbv1.PodGroupSpec{Queue: "q1"},
+	})
+	pod := buildPod("c1", "p1", "n1", v1.PodRunning, buildResourceList("1", "1G"),
+		[]metav1.OwnerReference{}, map[string]string{})
+	pod.Annotations = map[string]string{kbv1.GroupNameAnnotationKey: "pg1"}
+	sc.AddPod(pod)
+	task := api.NewTaskInfo(pod)
+
+	// The rejection is returned by Evict, and the task is kept running.
+	if err := sc.Evict(task, "Preempted by Task <c1/preemptor>"); !apierrors.IsTooManyRequests(err) {
+		t.Fatalf("expected the eviction to be rejected, got %v", err)
+	}
+	if status := sc.Jobs[task.Job].Tasks[task.UID].Status; status != api.Running {
+		t.Errorf("expected task %v, got %v", api.Running, status)
+	}
+	if releasing := sc.Nodes["n1"].Releasing; !releasing.IsEmpty() {
+		t.Errorf("expected no resources releasing on node <n1>, got %v", releasing)
+	}
+	if !sc.EvictionRejected(task) {
+		t.Errorf("expected the eviction of task to be recorded as rejected")
+	}
+
+	// The rejections which expired are cleaned up even if never looked up.
+	sc.evictionRejected[task.UID] = time.Now().Add(-time.Second)
+	sc.cleanupEvictionRejected()
+	if len(sc.evictionRejected) != 0 {
+		t.Errorf("expected the expired rejections cleaned up, got %v", sc.evictionRejected)
+	}
+}
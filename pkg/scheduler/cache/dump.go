@@ -0,0 +1,280 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"sort"
+
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/kubernetes-sigs/kube-batch/pkg/apis/scheduling/v1alpha1"
+	kbapi "github.com/kubernetes-sigs/kube-batch/pkg/scheduler/api"
+)
+
+// DumpFilter filters the objects in the dump of cache; empty field matches all.
+type DumpFilter struct {
+	// Namespace filters the jobs and tasks by namespace.
+	Namespace string
+	// Queue filters the queues, and the jobs and tasks by their queue.
+	Queue string
+	// Node filters the nodes, the tasks by the node they are on, and the jobs
+	// and queues by their tasks on the node.
+	Node string
+}
+
+// Dump is the state of cache.
+type Dump struct {
+	Jobs            []JobDump           `json:"jobs"`
+	Nodes           []NodeDump          `json:"nodes"`
+	Queues          []QueueDump         `json:"queues"`
+	PriorityClasses []PriorityClassDump `json:"priorityClasses"`
+}
+
+// JobDump is the state of a job in cache.
+type JobDump struct {
+	UID           kbapi.JobID            `json:"uid"`
+	Namespace     string                 `json:"namespace"`
+	Name          string                 `json:"name"`
+	Queue         kbapi.QueueID          `json:"queue"`
+	Priority      int32                  `json:"priority"`
+	MinAvailable  int32                  `json:"minAvailable"`
+	PodGroupPhase v1alpha1.PodGroupPhase `json:"podGroupPhase,omitempty"`
+	Allocated     v1.ResourceList        `json:"allocated"`
+	TotalRequest  v1.ResourceList        `json:"totalRequest"`
+	Tasks         []TaskDump             `json:"tasks,omitempty"`
+}
+
+// TaskDump is the state of a task in cache, whose status may be different from its pod.
+type TaskDump struct {
+	UID       kbapi.TaskID    `json:"uid"`
+	Namespace string          `json:"namespace"`
+	Name      string          `json:"name"`
+	Job       kbapi.JobID     `json:"job"`
+	Status    string          `json:"status"`
+	PodPhase  v1.PodPhase     `json:"podPhase"`
+	NodeName  string          `json:"nodeName,omitempty"`
+	Priority  int32           `json:"priority"`
+	Resreq    v1.ResourceList `json:"resreq"`
+}
+
+// NodeDump is the state of a node in cache.
+type NodeDump struct {
+	Name        string          `json:"name"`
+	Phase       string          `json:"phase"`
+	Reason      string          `json:"reason,omitempty"`
+	Idle        v1.ResourceList `json:"idle"`
+	Used        v1.ResourceList `json:"used"`
+	Releasing   v1.ResourceList `json:"releasing"`
+	Allocatable v1.ResourceList `json:"allocatable"`
+	Tasks       []kbapi.TaskID  `json:"tasks,omitempty"`
+}
+
+// QueueDump is the state of a queue in cache.
+type QueueDump struct {
+	Name   string              `json:"name"`
+	Weight int32               `json:"weight"`
+	Parent kbapi.QueueID       `json:"parent,omitempty"`
+	State  v1alpha1.QueueState `json:"state"`
+}
+
+// PriorityClassDump is a priority class in cache.
+type PriorityClassDump struct {
+	Name          string `json:"name"`
+	Value         int32  `json:"value"`
+	GlobalDefault bool   `json:"globalDefault"`
+}
+
+// Dump returns the state of cache which matches the filter; the objects are
+// sorted by name, so the dumps can be compared.
+func (sc *SchedulerCache) Dump(filter DumpFilter) *Dump {
+	dump := sc.dump(filter)
+
+	// The cache is not locked while sorting, which is done on the copies.
+	for i := range dump.Jobs {
+		tasks := dump.Jobs[i].Tasks
+		sort.Slice(tasks, func(i, j int) bool {
+			return tasks[i].Name < tasks[j].Name
+		})
+	}
+	sort.Slice(dump.Jobs, func(i, j int) bool {
+		if dump.Jobs[i].Namespace != dump.Jobs[j].Namespace {
+			return dump.Jobs[i].Namespace < dump.Jobs[j].Namespace
+		}
+		return dump.Jobs[i].Name < dump.Jobs[j].Name
+	})
+
+	for i := range dump.Nodes {
+		tasks := dump.Nodes[i].Tasks
+		sort.Slice(tasks, func(i, j int) bool {
+			return tasks[i] < tasks[j]
+		})
+	}
+	sort.Slice(dump.Nodes, func(i, j int) bool {
+		return dump.Nodes[i].Name < dump.Nodes[j].Name
+	})
+
+	sort.Slice(dump.Queues, func(i, j int) bool {
+		return dump.Queues[i].Name < dump.Queues[j].Name
+	})
+
+	sort.Slice(dump.PriorityClasses, func(i, j int) bool {
+		return dump.PriorityClasses[i].Name < dump.PriorityClasses[j].Name
+	})
+
+	return dump
+}
+
+// dump copies the objects which match the filter under the lock of cache.
+func (sc *SchedulerCache) dump(filter DumpFilter) *Dump {
+	sc.Mutex.Lock()
+	defer sc.Mutex.Unlock()
+
+	dump := &Dump{
+		Jobs:            []JobDump{},
+		Nodes:           []NodeDump{},
+		Queues:          []QueueDump{},
+		PriorityClasses: []PriorityClassDump{},
+	}
+
+	// The queues of jobs with tasks on the node, if filtered by node.
+	nodeQueues := map[kbapi.QueueID]bool{}
+
+	for _, job := range sc.Jobs {
+		if !filter.matchJob(job) {
+			continue
+		}
+
+		jd := JobDump{
+			UID:          job.UID,
+			Namespace:    job.Namespace,
+			Name:         job.Name,
+			Queue:        job.Queue,
+			Priority:     job.Priority,
+			MinAvailable: job.MinAvailable,
+			Allocated:    job.Allocated.ResourceList(),
+			TotalRequest: job.TotalRequest.ResourceList(),
+		}
+		if job.PodGroup != nil {
+			jd.PodGroupPhase = job.PodGroup.Status.Phase
+		}
+		for _, task := range job.Tasks {
+			if len(filter.Node) != 0 && task.NodeName != filter.Node {
+				continue
+			}
+			jd.Tasks = append(jd.Tasks, newTaskDump(task))
+		}
+		// Only the jobs with tasks on the node are dumped if filtered by node.
+		if len(filter.Node) != 0 && len(jd.Tasks) == 0 {
+			continue
+		}
+		nodeQueues[job.Queue] = true
+
+		dump.Jobs = append(dump.Jobs, jd)
+	}
+
+	for _, node := range sc.Nodes {
+		if len(filter.Node) != 0 && node.Name != filter.Node {
+			continue
+		}
+
+		nd := NodeDump{
+			Name:        node.Name,
+			Phase:       node.State.Phase.String(),
+			Reason:      node.State.Reason,
+			Idle:        node.Idle.ResourceList(),
+			Used:        node.Used.ResourceList(),
+			Releasing:   node.Releasing.ResourceList(),
+			Allocatable: node.Allocatable.ResourceList(),
+		}
+		for _, task := range node.Tasks {
+			if !filter.matchTask(sc, task) {
+				continue
+			}
+			nd.Tasks = append(nd.Tasks, task.UID)
+		}
+
+		dump.Nodes = append(dump.Nodes, nd)
+	}
+
+	for _, queue := range sc.Queues {
+		if len(filter.Queue) != 0 && queue.Name != filter.Queue {
+			continue
+		}
+		if len(filter.Node) != 0 && !nodeQueues[queue.UID] {
+			continue
+		}
+
+		dump.Queues = append(dump.Queues, QueueDump{
+			Name:   queue.Name,
+			Weight: queue.Weight,
+			Parent: queue.Parent,
+			State:  sc.queueState(queue),
+		})
+	}
+
+	for _, pc := range sc.PriorityClasses {
+		dump.PriorityClasses = append(dump.PriorityClasses, PriorityClassDump{
+			Name:          pc.Name,
+			Value:         pc.Value,
+			GlobalDefault: pc.GlobalDefault,
+		})
+	}
+
+	return dump
+}
+
+func newTaskDump(task *kbapi.TaskInfo) TaskDump {
+	td := TaskDump{
+		UID:       task.UID,
+		Namespace: task.Namespace,
+		Name:      task.Name,
+		Job:       task.Job,
+		Status:    task.Status.String(),
+		NodeName:  task.NodeName,
+		Priority:  task.Priority,
+		Resreq:    task.Resreq.ResourceList(),
+	}
+	if task.Pod != nil {
+		td.PodPhase = task.Pod.Status.Phase
+	}
+	return td
+}
+
+func (f *DumpFilter) matchJob(job *kbapi.JobInfo) bool {
+	if len(f.Namespace) != 0 && job.Namespace != f.Namespace {
+		return false
+	}
+	if len(f.Queue) != 0 && string(job.Queue) != f.Queue {
+		return false
+	}
+	return true
+}
+
+// matchTask returns whether the task on node matches the filter of namespace and
+// queue; the queue of task is the queue of its job.
+func (f *DumpFilter) matchTask(sc *SchedulerCache, task *kbapi.TaskInfo) bool {
+	if len(f.Namespace) != 0 && task.Namespace != f.Namespace {
+		return false
+	}
+	if len(f.Queue) != 0 {
+		job, found := sc.Jobs[task.Job]
+		if !found || string(job.Queue) != f.Queue {
+			return false
+		}
+	}
+	return true
+}
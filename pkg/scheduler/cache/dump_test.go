@@ -0,0 +1,151 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"reflect"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/api/scheduling/v1beta1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kbv1 "github.com/kubernetes-sigs/kube-batch/pkg/apis/scheduling/v1alpha1"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/api"
+)
+
+func TestDump(t *testing.T) {
+	sc := NewOfflineCache("kube-batch", "default")
+
+	alloc := v1.ResourceList{
+		v1.ResourceCPU:    resource.MustParse("4"),
+		v1.ResourceMemory: resource.MustParse("4Gi"),
+	}
+	req := v1.ResourceList{
+		v1.ResourceCPU:    resource.MustParse("1"),
+		v1.ResourceMemory: resource.MustParse("1Gi"),
+	}
+	sc.AddNode(buildNode("n1", alloc))
+	sc.AddNode(buildNode("n2", alloc))
+	for _, q := range []string{"q1", "q2"} {
+		sc.AddQueue(&kbv1.Queue{
+			ObjectMeta: metav1.ObjectMeta{Name: q},
+			Spec:       kbv1.QueueSpec{Weight: 1},
+		})
+	}
+	sc.AddPriorityClass(&v1beta1.PriorityClass{
+		ObjectMeta: metav1.ObjectMeta{Name: "high"},
+		Value:      100,
+	})
+	sc.AddPodGroup(&kbv1.PodGroup{
+		ObjectMeta: metav1.ObjectMeta{Name: "pg1", Namespace: "c1"},
+		Spec:       kbv1.PodGroupSpec{MinMember: 2, Queue: "q1"},
+		Status:     kbv1.PodGroupStatus{Phase: kbv1.PodGroupRunning},
+	})
+	sc.AddPodGroup(&kbv1.PodGroup{
+		ObjectMeta: metav1.ObjectMeta{Name: "pg2", Namespace: "c2"},
+		Spec:       kbv1.PodGroupSpec{MinMember: 1, Queue: "q2"},
+		Status:     kbv1.PodGroupStatus{Phase: kbv1.PodGroupPending},
+	})
+	for _, p := range []struct{ ns, name, node, pg string }{
+		{"c1", "p1", "n1", "pg1"},
+		{"c1", "p2", "n2", "pg1"},
+		{"c2", "p3", "", "pg2"},
+	} {
+		phase := v1.PodRunning
+		if len(p.node) == 0 {
+			phase = v1.PodPending
+		}
+		pod := buildPod(p.ns, p.name, p.node, phase, req, []metav1.OwnerReference{}, map[string]string{})
+		pod.Annotations = map[string]string{kbv1.GroupNameAnnotationKey: p.pg}
+		sc.AddPod(pod)
+	}
+
+	tests := []struct {
+		name   string
+		filter DumpFilter
+		jobs   map[string][]string
+		nodes  map[string][]api.TaskID
+		queues []string
+	}{
+		{
+			name:   "all",
+			filter: DumpFilter{},
+			jobs:   map[string][]string{"pg1": {"p1", "p2"}, "pg2": {"p3"}},
+			nodes:  map[string][]api.TaskID{"n1": {"c1-p1"}, "n2": {"c1-p2"}},
+			queues: []string{"q1", "q2"},
+		},
+		{
+			name:   "filter by namespace",
+			filter: DumpFilter{Namespace: "c2"},
+			jobs:   map[string][]string{"pg2": {"p3"}},
+			nodes:  map[string][]api.TaskID{"n1": nil, "n2": nil},
+			queues: []string{"q1", "q2"},
+		},
+		{
+			name:   "filter by queue",
+			filter: DumpFilter{Queue: "q1"},
+			jobs:   map[string][]string{"pg1": {"p1", "p2"}},
+			nodes:  map[string][]api.TaskID{"n1": {"c1-p1"}, "n2": {"c1-p2"}},
+			queues: []string{"q1"},
+		},
+		{
+			name:   "filter by node",
+			filter: DumpFilter{Node: "n2"},
+			jobs:   map[string][]string{"pg1": {"p2"}},
+			nodes:  map[string][]api.TaskID{"n2": {"c1-p2"}},
+			queues: []string{"q1"},
+		},
+	}
+
+	for _, test := range tests {
+		dump := sc.Dump(test.filter)
+
+		jobs := map[string][]string{}
+		for _, job := range dump.Jobs {
+			var tasks []string
+			for _, task := range job.Tasks {
+				tasks = append(tasks, task.Name)
+			}
+			jobs[job.Name] = tasks
+		}
+		if !reflect.DeepEqual(test.jobs, jobs) {
+			t.Errorf("case <%s>: expected jobs %v, got %v", test.name, test.jobs, jobs)
+		}
+
+		nodes := map[string][]api.TaskID{}
+		for _, node := range dump.Nodes {
+			nodes[node.Name] = node.Tasks
+		}
+		if !reflect.DeepEqual(test.nodes, nodes) {
+			t.Errorf("case <%s>: expected nodes %v, got %v", test.name, test.nodes, nodes)
+		}
+
+		var queues []string
+		for _, queue := range dump.Queues {
+			queues = append(queues, queue.Name)
+		}
+		if !reflect.DeepEqual(test.queues, queues) {
+			t.Errorf("case <%s>: expected queues %v, got %v", test.name, test.queues, queues)
+		}
+
+		if len(dump.PriorityClasses) != 1 || dump.PriorityClasses[0].Value != 100 {
+			t.Errorf("case <%s>: expected priority class high, got %v", test.name, dump.PriorityClasses)
+		}
+	}
+}
@@ -480,8 +480,17 @@ func (sc *SchedulerCache) DeletePodGroup(obj interface{}) {
 	return
 }
 
+func pdbKey(pdb *policyv1.PodDisruptionBudget) string {
+	return fmt.Sprintf("%s/%s", pdb.Namespace, pdb.Name)
+}
+
 // Assumes that lock is already acquired.
 func (sc *SchedulerCache) setPDB(pdb *policyv1.PodDisruptionBudget) error {
+	if sc.PDBs == nil {
+		sc.PDBs = make(map[string]*policyv1.PodDisruptionBudget)
+	}
+	sc.PDBs[pdbKey(pdb)] = pdb
+
 	job := kbapi.JobID(utils.GetController(pdb))
 
 	if len(job) == 0 {
@@ -506,6 +515,8 @@ func (sc *SchedulerCache) updatePDB(oldPDB, newPDB *policyv1.PodDisruptionBudget
 
 // Assumes that lock is already acquired.
 func (sc *SchedulerCache) deletePDB(pdb *policyv1.PodDisruptionBudget) error {
+	delete(sc.PDBs, pdbKey(pdb))
+
 	jobID := kbapi.JobID(utils.GetController(pdb))
 
 	job, found := sc.Jobs[jobID]
@@ -41,6 +41,10 @@ type Cache interface {
 	// Evict evicts the task to release resources.
 	Evict(task *api.TaskInfo, reason string) error
 
+	// EvictionRejected returns whether the eviction of task was rejected recently,
+	// e.g. by PodDisruptionBudget.
+	EvictionRejected(task *api.TaskInfo) bool
+
 	// RecordJobStatusEvent records related events according to job status.
 	// Deprecated: remove it after removed PDB support.
 	RecordJobStatusEvent(job *api.JobInfo)
@@ -48,17 +52,24 @@ type Cache interface {
 	// UpdateJobStatus puts job in backlog for a while.
 	UpdateJobStatus(job *api.JobInfo, updatePG bool) (*api.JobInfo, error)
 
+	// UpdateQueueStatus updates the status of queue.
+	UpdateQueueStatus(queue *api.QueueInfo) error
+
 	// AllocateVolumes allocates volume on the host to the task
 	AllocateVolumes(task *api.TaskInfo, hostname string) error
 
 	// BindVolumes binds volumes to the task
 	BindVolumes(task *api.TaskInfo) error
+
+	// RevertVolumes reverts the volumes allocated to the task
+	RevertVolumes(task *api.TaskInfo) error
 }
 
 // VolumeBinder interface for allocate and bind volumes
 type VolumeBinder interface {
 	AllocateVolumes(task *api.TaskInfo, hostname string) error
 	BindVolumes(task *api.TaskInfo) error
+	RevertVolumes(task *api.TaskInfo) error
 }
 
 //Binder interface for binding task and hostname
@@ -66,13 +77,15 @@ type Binder interface {
 	Bind(task *v1.Pod, hostname string) error
 }
 
-// Evictor interface for evict pods
+// Evictor interface for evict pods; the pods are deleted with the grace period
+// if it's not nil.
 type Evictor interface {
-	Evict(pod *v1.Pod) error
+	Evict(pod *v1.Pod, gracePeriodSeconds *int64) error
 }
 
 // StatusUpdater updates pod with given PodCondition
 type StatusUpdater interface {
 	UpdatePodCondition(pod *v1.Pod, podCondition *v1.PodCondition) (*v1.Pod, error)
 	UpdatePodGroup(pg *v1alpha1.PodGroup) (*v1alpha1.PodGroup, error)
+	UpdateQueueStatus(queue *v1alpha1.Queue) (*v1alpha1.Queue, error)
 }
@@ -22,6 +22,31 @@ type SchedulerConfiguration struct {
 	Actions string `yaml:"actions"`
 	// Tiers defines plugins in different tiers
 	Tiers []Tier `yaml:"tiers"`
+	// NodeSearch defines how to search nodes for tasks
+	NodeSearch NodeSearchConfiguration `yaml:",inline"`
+	// Configurations defines the arguments of actions
+	Configurations []Configuration `yaml:"configurations"`
+}
+
+// Configuration defines the arguments of an action
+type Configuration struct {
+	// Name is the name of action
+	Name string `yaml:"name"`
+	// Arguments defines the different arguments that can be given to the action
+	Arguments map[string]string `yaml:"arguments"`
+}
+
+// NodeSearchConfiguration defines how to search nodes for tasks, which is used to
+// speed up scheduling in large clusters.
+type NodeSearchConfiguration struct {
+	// PercentageOfNodesToFind is the percentage of all nodes that once found feasible
+	// for a task, the scheduler stops searching more feasible nodes; 100 means all nodes
+	PercentageOfNodesToFind int32 `yaml:"percentageOfNodesToFind"`
+	// MinNodesToFind is the minimum number of feasible nodes to find for a task,
+	// regardless of PercentageOfNodesToFind; 0 means no minimum
+	MinNodesToFind *int32 `yaml:"minNodesToFind"`
+	// NodeWorkers is the number of workers to predicate and prioritize nodes in parallel
+	NodeWorkers int `yaml:"nodeWorkers"`
 }
 
 // Tier defines plugin tier
@@ -39,6 +64,8 @@ type PluginOption struct {
 	EnabledJobReady *bool `yaml:"enableJobReady"`
 	// EnabledJobPipelined defines whether jobPipelinedFn is enabled
 	EnabledJobPipelined *bool `yaml:"enableJobPipelined"`
+	// EnabledJobStarving defines whether jobStarvingFn is enabled
+	EnabledJobStarving *bool `yaml:"enableJobStarving"`
 	// EnabledTaskOrder defines whether taskOrderFn is enabled
 	EnabledTaskOrder *bool `yaml:"enableTaskOrder"`
 	// EnabledPreemptable defines whether preemptableFn is enabled
@@ -0,0 +1,49 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package debug
+
+import (
+	"net/http"
+
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/cache"
+)
+
+// CachePath is the path of the state of scheduler cache.
+const CachePath = "/debug/cache"
+
+// CacheDumper dumps the state of scheduler cache, e.g. cache.SchedulerCache.
+type CacheDumper interface {
+	Dump(filter cache.DumpFilter) *cache.Dump
+}
+
+// CacheHandler serves the state of scheduler cache at CachePath; the objects can
+// be filtered by the query parameters namespace, queue and node.
+func CacheHandler(dumper CacheDumper) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "only GET is supported", http.StatusMethodNotAllowed)
+			return
+		}
+
+		query := r.URL.Query()
+		writeJSON(w, dumper.Dump(cache.DumpFilter{
+			Namespace: query.Get("namespace"),
+			Queue:     query.Get("queue"),
+			Node:      query.Get("node"),
+		}))
+	})
+}
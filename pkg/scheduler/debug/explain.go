@@ -0,0 +1,72 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package debug
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/golang/glog"
+
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/framework"
+)
+
+// ExplainPath is the path prefix of the explanations of PodGroups.
+const ExplainPath = "/debug/explain/"
+
+// ExplainHandler serves the explanation of the scheduling decisions of a PodGroup
+// in the last session at ExplainPath{namespace}/{podgroup}.
+func ExplainHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "only GET is supported", http.StatusMethodNotAllowed)
+			return
+		}
+
+		parts := strings.Split(strings.TrimPrefix(r.URL.Path, ExplainPath), "/")
+		if len(parts) != 2 || len(parts[0]) == 0 || len(parts[1]) == 0 {
+			http.Error(w, fmt.Sprintf("expected path %s{namespace}/{podgroup}", ExplainPath),
+				http.StatusBadRequest)
+			return
+		}
+
+		explanation, found := framework.GetJobExplanation(parts[0], parts[1])
+		if !found {
+			http.Error(w, fmt.Sprintf("PodGroup <%s/%s> is not found in the last session",
+				parts[0], parts[1]), http.StatusNotFound)
+			return
+		}
+
+		writeJSON(w, explanation)
+	})
+}
+
+func writeJSON(w http.ResponseWriter, obj interface{}) {
+	data, err := json.MarshalIndent(obj, "", "  ")
+	if err != nil {
+		glog.Errorf("Failed to marshal %T: %v", obj, err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if _, err := w.Write(data); err != nil {
+		glog.Errorf("Failed to write response: %v", err)
+	}
+}
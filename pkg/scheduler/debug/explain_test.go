@@ -0,0 +1,128 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package debug
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	kbv1 "github.com/kubernetes-sigs/kube-batch/pkg/apis/scheduling/v1alpha1"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/actions/allocate"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/cache"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/conf"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/framework"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/plugins"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/util"
+)
+
+func TestExplainHandler(t *testing.T) {
+	sc := cache.NewOfflineCache("kube-batch", "default")
+	sc.Binder = &util.FakeBinder{
+		Binds:   map[string]string{},
+		Channel: make(chan string, 10),
+	}
+	sc.StatusUpdater = &util.FakeStatusUpdater{}
+	sc.VolumeBinder = &util.FakeVolumeBinder{}
+	sc.Recorder = &record.FakeRecorder{}
+
+	alloc := util.BuildResourceList("2", "4Gi")
+	alloc[v1.ResourcePods] = resource.MustParse("10")
+	sc.AddNode(util.BuildNode("n1", alloc, map[string]string{"zone": "a"}))
+	sc.AddQueue(&kbv1.Queue{
+		ObjectMeta: metav1.ObjectMeta{Name: "default"},
+		Spec:       kbv1.QueueSpec{Weight: 1},
+	})
+	sc.AddPodGroup(&kbv1.PodGroup{
+		ObjectMeta: metav1.ObjectMeta{Name: "pg1", Namespace: "c1"},
+		Spec:       kbv1.PodGroupSpec{MinMember: 1, Queue: "default"},
+		Status:     kbv1.PodGroupStatus{Phase: kbv1.PodGroupInqueue},
+	})
+	sc.AddPod(util.BuildPod("c1", "p1", "", v1.PodPending,
+		util.BuildResourceList("1", "1Gi"), "pg1", make(map[string]string), map[string]string{"zone": "b"}))
+
+	tiers := []conf.Tier{
+		{
+			Plugins: []conf.PluginOption{
+				{Name: "gang"},
+				{Name: "predicates"},
+			},
+		},
+	}
+	for i := range tiers[0].Plugins {
+		plugins.ApplyPluginConfDefaults(&tiers[0].Plugins[i])
+	}
+
+	framework.EnableExplanations(true)
+	defer framework.EnableExplanations(false)
+
+	ssn := framework.OpenSession(sc, tiers)
+	allocate.New().Execute(ssn)
+	framework.CloseSession(ssn)
+
+	tests := []struct {
+		name string
+		path string
+		code int
+	}{
+		{
+			name: "explain podgroup",
+			path: "/debug/explain/c1/pg1",
+			code: http.StatusOK,
+		},
+		{
+			name: "podgroup not found",
+			path: "/debug/explain/c1/pg2",
+			code: http.StatusNotFound,
+		},
+		{
+			name: "invalid path",
+			path: "/debug/explain/c1",
+			code: http.StatusBadRequest,
+		},
+	}
+
+	for _, test := range tests {
+		rec := httptest.NewRecorder()
+		ExplainHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, test.path, nil))
+		if rec.Code != test.code {
+			t.Errorf("case <%s>: expected status %d, got %d: %s", test.name, test.code, rec.Code, rec.Body.String())
+			continue
+		}
+		if rec.Code != http.StatusOK {
+			continue
+		}
+
+		explanation := &framework.JobExplanation{}
+		if err := json.Unmarshal(rec.Body.Bytes(), explanation); err != nil {
+			t.Fatalf("case <%s>: failed to decode explanation: %v", test.name, err)
+		}
+		task, found := explanation.Tasks["p1"]
+		if !found || len(task.FitErrors["n1"]) == 0 {
+			t.Errorf("case <%s>: expected predicate failures of p1 on n1, got %+v", test.name, task)
+		}
+		if len(explanation.JobFitErrors) == 0 {
+			t.Errorf("case <%s>: expected job fit errors", test.name)
+		}
+	}
+}
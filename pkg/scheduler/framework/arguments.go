@@ -18,8 +18,11 @@ package framework
 
 import (
 	"strconv"
+	"time"
 
 	"github.com/golang/glog"
+
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/conf"
 )
 
 // Arguments map
@@ -64,3 +67,55 @@ func (a Arguments) GetBool(ptr *bool, key string) {
 
 	*ptr = value
 }
+
+//GetFloat64 get the float64 value from string
+func (a Arguments) GetFloat64(ptr *float64, key string) {
+	if ptr == nil {
+		return
+	}
+
+	argv, ok := a[key]
+	if !ok || argv == "" {
+		return
+	}
+
+	value, err := strconv.ParseFloat(argv, 64)
+	if err != nil {
+		glog.Warningf("Could not parse argument: %s for key %s, with err %v", argv, key, err)
+		return
+	}
+
+	*ptr = value
+}
+
+//GetDuration get the duration value from string, e.g. "30m"
+func (a Arguments) GetDuration(ptr *time.Duration, key string) {
+	if ptr == nil {
+		return
+	}
+
+	argv, ok := a[key]
+	if !ok || argv == "" {
+		return
+	}
+
+	value, err := time.ParseDuration(argv)
+	if err != nil {
+		glog.Warningf("Could not parse argument: %s for key %s, with err %v", argv, key, err)
+		return
+	}
+
+	*ptr = value
+}
+
+// GetArgOfActionFromConf returns the arguments of the action in configurations,
+// or nil if the action is not configured.
+func GetArgOfActionFromConf(configurations []conf.Configuration, actionName string) Arguments {
+	for _, c := range configurations {
+		if c.Name == actionName {
+			return c.Arguments
+		}
+	}
+
+	return nil
+}
@@ -18,6 +18,7 @@ package framework
 
 import (
 	"testing"
+	"time"
 )
 
 type GetIntTestCases struct {
@@ -74,3 +75,73 @@ func TestArgumentsGetInt(t *testing.T) {
 		}
 	}
 }
+
+func TestArgumentsGetDuration(t *testing.T) {
+	key := "durationkey"
+
+	cases := []struct {
+		arg         Arguments
+		baseValue   time.Duration
+		expectValue time.Duration
+	}{
+		{
+			arg:         Arguments{"anotherkey": "1m"},
+			baseValue:   time.Hour,
+			expectValue: time.Hour,
+		},
+		{
+			arg:         Arguments{key: "30m"},
+			baseValue:   time.Hour,
+			expectValue: 30 * time.Minute,
+		},
+		{
+			arg:         Arguments{key: "30"},
+			baseValue:   time.Hour,
+			expectValue: time.Hour,
+		},
+	}
+
+	for index, c := range cases {
+		baseValue := c.baseValue
+		c.arg.GetDuration(nil, key)
+		c.arg.GetDuration(&baseValue, key)
+		if baseValue != c.expectValue {
+			t.Errorf("index %d, value should be %v, but not %v", index, c.expectValue, baseValue)
+		}
+	}
+}
+
+func TestArgumentsGetFloat64(t *testing.T) {
+	key := "floatkey"
+
+	cases := []struct {
+		arg         Arguments
+		baseValue   float64
+		expectValue float64
+	}{
+		{
+			arg:         Arguments{"anotherkey": "1.5"},
+			baseValue:   1.2,
+			expectValue: 1.2,
+		},
+		{
+			arg:         Arguments{key: "1.5"},
+			baseValue:   1.2,
+			expectValue: 1.5,
+		},
+		{
+			arg:         Arguments{key: "errorvalue"},
+			baseValue:   1.2,
+			expectValue: 1.2,
+		},
+	}
+
+	for index, c := range cases {
+		baseValue := c.baseValue
+		c.arg.GetFloat64(nil, key)
+		c.arg.GetFloat64(&baseValue, key)
+		if baseValue != c.expectValue {
+			t.Errorf("index %d, value should be %v, but not %v", index, c.expectValue, baseValue)
+		}
+	}
+}
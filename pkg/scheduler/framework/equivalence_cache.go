@@ -0,0 +1,179 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package framework
+
+import (
+	"hash/fnv"
+	"sync"
+
+	"github.com/golang/glog"
+
+	v1 "k8s.io/api/core/v1"
+	hashutil "k8s.io/kubernetes/pkg/util/hash"
+
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/api"
+)
+
+// equivalenceCache caches the predicate results of equivalent tasks, i.e. the
+// tasks created from the same pod template, on each node in a session.
+type equivalenceCache struct {
+	sync.RWMutex
+
+	// results is the predicate results indexed by node name and equivalence hash.
+	results map[string]map[uint64]error
+	// hashes is the equivalence hash of tasks; the task is not cacheable if not found.
+	hashes map[api.TaskID]uint64
+	// uncacheable is the tasks whose predicate results can not be cached.
+	uncacheable map[api.TaskID]bool
+}
+
+func newEquivalenceCache() *equivalenceCache {
+	return &equivalenceCache{
+		results:     map[string]map[uint64]error{},
+		hashes:      map[api.TaskID]uint64{},
+		uncacheable: map[api.TaskID]bool{},
+	}
+}
+
+// equivalencePod is the fields of pod which decide the predicate results of it.
+type equivalencePod struct {
+	Namespace   string
+	Labels      map[string]string
+	Annotations map[string]string
+	Spec        v1.PodSpec
+}
+
+// hasPodAffinity checks whether the pod has pod affinity or anti-affinity, whose
+// predicate results depend on the pods on other nodes.
+func hasPodAffinity(pod *v1.Pod) bool {
+	affinity := pod.Spec.Affinity
+	return affinity != nil && (affinity.PodAffinity != nil || affinity.PodAntiAffinity != nil)
+}
+
+// equivalenceHash returns the equivalence hash of task, and whether its predicate
+// results can be cached.
+func (ec *equivalenceCache) equivalenceHash(task *api.TaskInfo) (uint64, bool) {
+	ec.RLock()
+	hash, found := ec.hashes[task.UID]
+	uncacheable := ec.uncacheable[task.UID]
+	ec.RUnlock()
+
+	if found || uncacheable {
+		return hash, found
+	}
+
+	ec.Lock()
+	defer ec.Unlock()
+
+	pod := task.Pod
+	if pod == nil || hasPodAffinity(pod) {
+		ec.uncacheable[task.UID] = true
+		return 0, false
+	}
+
+	spec := pod.Spec.DeepCopy()
+	// The node name and hostname are different for each replica, and do not
+	// impact on predicates.
+	spec.NodeName = ""
+	spec.Hostname = ""
+
+	hasher := fnv.New64a()
+	hashutil.DeepHashObject(hasher, &equivalencePod{
+		Namespace:   pod.Namespace,
+		Labels:      pod.Labels,
+		Annotations: pod.Annotations,
+		Spec:        *spec,
+	})
+	hash = hasher.Sum64()
+	ec.hashes[task.UID] = hash
+
+	return hash, true
+}
+
+// lookup returns the cached predicate result of task on the node.
+func (ec *equivalenceCache) lookup(task *api.TaskInfo, node *api.NodeInfo) (bool, error) {
+	hash, cacheable := ec.equivalenceHash(task)
+	if !cacheable {
+		return false, nil
+	}
+
+	ec.RLock()
+	defer ec.RUnlock()
+
+	err, found := ec.results[node.Name][hash]
+	if !found {
+		return false, nil
+	}
+
+	// The FitError is built for the task which was predicated, rebuild it for this task.
+	if fe, ok := err.(*api.FitError); ok {
+		return true, api.NewFitError(task, node, fe.Reasons...)
+	}
+
+	return true, err
+}
+
+// update caches the predicate result of task on the node.
+func (ec *equivalenceCache) update(task *api.TaskInfo, node *api.NodeInfo, err error) {
+	hash, cacheable := ec.equivalenceHash(task)
+	if !cacheable {
+		return
+	}
+
+	ec.Lock()
+	defer ec.Unlock()
+
+	if _, found := ec.results[node.Name]; !found {
+		ec.results[node.Name] = map[uint64]error{}
+	}
+	ec.results[node.Name][hash] = err
+}
+
+// invalidateNode removes the cached predicate results on the node.
+func (ec *equivalenceCache) invalidateNode(nodeName string) {
+	ec.Lock()
+	defer ec.Unlock()
+
+	delete(ec.results, nodeName)
+}
+
+// invalidateAll removes all cached predicate results.
+func (ec *equivalenceCache) invalidateAll() {
+	ec.Lock()
+	defer ec.Unlock()
+
+	ec.results = map[string]map[uint64]error{}
+}
+
+// onTaskUpdated invalidates the cached predicate results impacted by the task
+// which is allocated to or deallocated from a node.
+func (ec *equivalenceCache) onTaskUpdated(event *Event) {
+	task := event.Task
+
+	// The pod with affinity/anti-affinity impacts on the predicate results of
+	// others on other nodes, e.g. the nodes in the same zone.
+	if task.Pod != nil && hasPodAffinity(task.Pod) {
+		glog.V(4).Infof("Invalidate all predicate results for Task <%s/%s> with pod affinity.",
+			task.Namespace, task.Name)
+		ec.invalidateAll()
+		return
+	}
+
+	glog.V(4).Infof("Invalidate predicate results on Node <%s> for Task <%s/%s>.",
+		task.NodeName, task.Namespace, task.Name)
+	ec.invalidateNode(task.NodeName)
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package framework
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/api"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/util"
+)
+
+func TestEquivalenceCache(t *testing.T) {
+	buildTask := func(name, nodeName string) *api.TaskInfo {
+		pod := util.BuildPod("c1", name, nodeName, v1.PodPending, util.BuildResourceList("1", "1G"), "pg1",
+			map[string]string{"app": "test"}, map[string]string{"disk": "ssd"})
+		return api.NewTaskInfo(pod)
+	}
+
+	n1 := api.NewNodeInfo(util.BuildNode("n1", util.BuildResourceList("2", "2G"), nil))
+	n2 := api.NewNodeInfo(util.BuildNode("n2", util.BuildResourceList("2", "2G"), nil))
+
+	ec := newEquivalenceCache()
+
+	t1 := buildTask("p1", "")
+	t2 := buildTask("p2", "")
+	ec.update(t1, n1, api.NewFitError(t1, n1, "node(s) didn't match node selector"))
+	ec.update(t1, n2, nil)
+
+	found, err := ec.lookup(t2, n1)
+	if !found {
+		t.Fatalf("expected predicate result of equivalent task on <n1> is cached")
+	}
+	if fe, ok := err.(*api.FitError); !ok || fe.Error() != api.NewFitError(t2, n1, "node(s) didn't match node selector").Error() {
+		t.Errorf("expected FitError of task <p2> on <n1>, got %v", err)
+	}
+	if found, err := ec.lookup(t2, n2); !found || err != nil {
+		t.Errorf("expected cached nil error of equivalent task on <n2>, got %v, %v", found, err)
+	}
+
+	// The task with different template is not equivalent.
+	t3 := buildTask("p3", "")
+	t3.Pod.Spec.NodeSelector = map[string]string{"disk": "hdd"}
+	if found, _ := ec.lookup(t3, n1); found {
+		t.Errorf("expected task with different template is not found in cache")
+	}
+
+	// The results on the node are invalidated when a task is allocated to it.
+	ec.onTaskUpdated(&Event{Task: buildTask("p4", "n1")})
+	if found, _ := ec.lookup(t2, n1); found {
+		t.Errorf("expected predicate results on <n1> are invalidated")
+	}
+	if found, _ := ec.lookup(t2, n2); !found {
+		t.Errorf("expected predicate results on <n2> are kept")
+	}
+
+	// The results on all nodes are invalidated by the task with pod affinity.
+	t5 := buildTask("p5", "n1")
+	t5.Pod.Spec.Affinity = &v1.Affinity{
+		PodAntiAffinity: &v1.PodAntiAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: []v1.PodAffinityTerm{
+				{
+					LabelSelector: &metav1.LabelSelector{
+						MatchLabels: map[string]string{"app": "test"},
+					},
+					TopologyKey: "zone",
+				},
+			},
+		},
+	}
+	ec.onTaskUpdated(&Event{Task: t5})
+	if found, _ := ec.lookup(t2, n2); found {
+		t.Errorf("expected predicate results on all nodes are invalidated")
+	}
+
+	// The predicate results of task with pod affinity are not cached.
+	ec.update(t5, n2, nil)
+	if found, _ := ec.lookup(t5, n2); found {
+		t.Errorf("expected predicate results of task with pod affinity are not cached")
+	}
+}
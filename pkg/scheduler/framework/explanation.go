@@ -0,0 +1,231 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package framework
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/api"
+)
+
+// PluginVerdict is the verdict of the plugin which rejected a job or queue.
+type PluginVerdict struct {
+	Plugin  string `json:"plugin"`
+	Reason  string `json:"reason,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// maxExplainedNodes is the max number of nodes in the explanation of a task, so
+// the explanations are bounded in large clusters.
+const maxExplainedNodes = 10
+
+// TaskExplanation explains the placement of a task in session.
+type TaskExplanation struct {
+	// FitErrors are the predicate failures of at most maxExplainedNodes nodes,
+	// which are the first ones by name.
+	FitErrors map[string][]string `json:"fitErrors,omitempty"`
+	// NodeScores are the scores of at most maxExplainedNodes feasible nodes with
+	// the highest scores given by PrioritizeNodes.
+	NodeScores map[string]float64 `json:"nodeScores,omitempty"`
+}
+
+// JobExplanation explains the scheduling decisions of a job in session.
+type JobExplanation struct {
+	Session   types.UID `json:"session"`
+	Timestamp time.Time `json:"timestamp"`
+
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Queue     string `json:"queue"`
+
+	// QueueOverused is the verdict of the plugin which reported the queue of job
+	// overused, nil if the queue is not overused.
+	QueueOverused *PluginVerdict `json:"queueOverused,omitempty"`
+	// JobValid is the verdict of the plugin which rejected the job in JobValid.
+	JobValid *PluginVerdict `json:"jobValid,omitempty"`
+	// JobEnqueueable is the verdict of the plugin which rejected the job in JobEnqueueable.
+	JobEnqueueable *PluginVerdict `json:"jobEnqueueable,omitempty"`
+	// JobFitErrors is the reason why the job is not ready, e.g. given by gang plugin.
+	JobFitErrors string `json:"jobFitErrors,omitempty"`
+
+	// Tasks are the explanations of tasks keyed by name.
+	Tasks map[string]*TaskExplanation `json:"tasks,omitempty"`
+}
+
+// The explanations of jobs in the last session, keyed by namespace/name; they
+// are only built if enabled.
+var (
+	explanations        = map[string]*JobExplanation{}
+	explanationsEnabled bool
+	explanationsLock    sync.RWMutex
+)
+
+// EnableExplanations enables or disables the explanations of the sessions opened
+// afterwards; the explanations of the last session are dropped if disabled.
+func EnableExplanations(enabled bool) {
+	explanationsLock.Lock()
+	defer explanationsLock.Unlock()
+
+	explanationsEnabled = enabled
+	if !enabled {
+		explanations = map[string]*JobExplanation{}
+	}
+}
+
+func isExplanationsEnabled() bool {
+	explanationsLock.RLock()
+	defer explanationsLock.RUnlock()
+
+	return explanationsEnabled
+}
+
+func explanationKey(namespace, name string) string {
+	return fmt.Sprintf("%s/%s", namespace, name)
+}
+
+// GetJobExplanation returns the explanation of the job (PodGroup) in the last session.
+func GetJobExplanation(namespace, name string) (*JobExplanation, bool) {
+	explanationsLock.RLock()
+	defer explanationsLock.RUnlock()
+
+	e, found := explanations[explanationKey(namespace, name)]
+	return e, found
+}
+
+// explaining returns whether the decisions of session are explained.
+func (ssn *Session) explaining() bool {
+	return ssn.explanations != nil
+}
+
+// explain returns the explanation of job in session, which is created if not found.
+func (ssn *Session) explain(job *api.JobInfo) *JobExplanation {
+	e, found := ssn.explanations[job.UID]
+	if !found {
+		e = &JobExplanation{
+			Session:   ssn.UID,
+			Namespace: job.Namespace,
+			Name:      job.Name,
+			Queue:     string(job.Queue),
+			Tasks:     map[string]*TaskExplanation{},
+		}
+		ssn.explanations[job.UID] = e
+	}
+	return e
+}
+
+func (e *JobExplanation) task(task *api.TaskInfo) *TaskExplanation {
+	te, found := e.Tasks[task.Name]
+	if !found {
+		te = &TaskExplanation{}
+		e.Tasks[task.Name] = te
+	}
+	return te
+}
+
+// RecordNodeScores records the highest scores of nodes given by PrioritizeNodes
+// for the task if the session is explained.
+func (ssn *Session) RecordNodeScores(task *api.TaskInfo, nodeScores map[float64][]*api.NodeInfo) {
+	if !ssn.explaining() {
+		return
+	}
+	job, found := ssn.Jobs[task.Job]
+	if !found {
+		return
+	}
+
+	var scores []float64
+	for score := range nodeScores {
+		scores = append(scores, score)
+	}
+	sort.Sort(sort.Reverse(sort.Float64Slice(scores)))
+
+	explained := map[string]float64{}
+	for _, score := range scores {
+		var names []string
+		for _, node := range nodeScores[score] {
+			names = append(names, node.Name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			if len(explained) >= maxExplainedNodes {
+				break
+			}
+			explained[name] = score
+		}
+	}
+	ssn.explain(job).task(task).NodeScores = explained
+}
+
+// limitFitErrors returns the fit errors of the first maxExplainedNodes nodes by name.
+func limitFitErrors(fitErrors map[string][]string) map[string][]string {
+	if len(fitErrors) <= maxExplainedNodes {
+		return fitErrors
+	}
+
+	var names []string
+	for name := range fitErrors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	limited := map[string][]string{}
+	for _, name := range names[:maxExplainedNodes] {
+		limited[name] = fitErrors[name]
+	}
+	return limited
+}
+
+// publishExplanations replaces the explanations of the last session by the
+// ones of this session if the session is explained.
+func (ssn *Session) publishExplanations() {
+	if !ssn.explaining() {
+		return
+	}
+	now := time.Now()
+
+	for _, job := range ssn.Jobs {
+		e := ssn.explain(job)
+		e.JobFitErrors = job.JobFitErrors
+		e.QueueOverused = ssn.queueOverused[job.Queue]
+
+		for uid, fitErrors := range job.NodesFitErrors {
+			task, found := job.Tasks[uid]
+			if !found || fitErrors == nil {
+				continue
+			}
+			e.task(task).FitErrors = limitFitErrors(fitErrors.NodeReasons())
+		}
+	}
+
+	// The jobs rejected by JobValid may be removed from session, so the result is
+	// built from all explanations instead of the jobs in session.
+	result := map[string]*JobExplanation{}
+	for _, e := range ssn.explanations {
+		e.Timestamp = now
+		result[explanationKey(e.Namespace, e.Name)] = e
+	}
+
+	explanationsLock.Lock()
+	defer explanationsLock.Unlock()
+	explanations = result
+}
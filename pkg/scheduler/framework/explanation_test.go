@@ -0,0 +1,210 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package framework
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kubernetes-sigs/kube-batch/pkg/apis/scheduling/v1alpha1"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/api"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/conf"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/util"
+)
+
+func TestJobExplanation(t *testing.T) {
+	p1 := util.BuildPod("c1", "p1", "", v1.PodPending, util.BuildResourceList("1", "1G"), "pg1", nil, nil)
+	pg1 := buildJob("pg1", v1alpha1.PodGroupInqueue, p1)
+	pg1.JobFitErrors = "1/1 tasks in gang unschedulable"
+	pg2 := buildJob("pg2", v1alpha1.PodGroupPending)
+
+	queue := api.NewQueueInfo(&v1alpha1.Queue{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "q1",
+		},
+	})
+	n1 := api.NewNodeInfo(util.BuildNode("n1", util.BuildResourceList("2", "2G"), nil))
+
+	ssn := &Session{
+		Jobs:   map[api.JobID]*api.JobInfo{pg1.UID: pg1, pg2.UID: pg2},
+		Queues: map[api.QueueID]*api.QueueInfo{queue.UID: queue},
+		Tiers: []conf.Tier{
+			{
+				Plugins: []conf.PluginOption{
+					{Name: "gang"},
+					{Name: "proportion"},
+				},
+			},
+		},
+		jobValidFns: map[string]api.ValidateExFn{
+			"gang": func(obj interface{}) *api.ValidateResult {
+				if job := obj.(*api.JobInfo); job.UID == pg2.UID {
+					return &api.ValidateResult{Pass: false, Reason: "NotEnoughPods", Message: "0/1 pods"}
+				}
+				return nil
+			},
+		},
+		jobEnqueueableFns: map[string]api.ValidateFn{
+			"proportion": func(obj interface{}) bool {
+				return false
+			},
+		},
+		overusedFns: map[string]api.ValidateFn{
+			"proportion": func(obj interface{}) bool {
+				return true
+			},
+		},
+		explanations:  map[api.JobID]*JobExplanation{},
+		queueOverused: map[api.QueueID]*PluginVerdict{},
+	}
+
+	for _, job := range ssn.Jobs {
+		ssn.JobValid(job)
+	}
+	ssn.JobEnqueueable(pg1)
+	ssn.Overused(queue)
+
+	task := pg1.Tasks[api.TaskID(p1.UID)]
+	task.Job = pg1.UID
+	fitErrors := api.NewFitErrors()
+	fitErrors.SetNodeError("n2", fmt.Errorf("node(s) didn't match node selector"))
+	pg1.NodesFitErrors[task.UID] = fitErrors
+	ssn.RecordNodeScores(task, map[float64][]*api.NodeInfo{10: {n1}})
+
+	ssn.publishExplanations()
+
+	e1, found := GetJobExplanation("c1", "pg1")
+	if !found {
+		t.Fatalf("expected explanation of c1/pg1")
+	}
+	if e1.JobValid != nil {
+		t.Errorf("expected c1/pg1 to pass JobValid, got %v", e1.JobValid)
+	}
+	if expected := (&PluginVerdict{Plugin: "proportion"}); !reflect.DeepEqual(expected, e1.JobEnqueueable) {
+		t.Errorf("expected JobEnqueueable verdict %v, got %v", expected, e1.JobEnqueueable)
+	}
+	if expected := (&PluginVerdict{Plugin: "proportion"}); !reflect.DeepEqual(expected, e1.QueueOverused) {
+		t.Errorf("expected QueueOverused verdict %v, got %v", expected, e1.QueueOverused)
+	}
+	if e1.JobFitErrors != pg1.JobFitErrors {
+		t.Errorf("expected JobFitErrors %q, got %q", pg1.JobFitErrors, e1.JobFitErrors)
+	}
+	expectedTask := &TaskExplanation{
+		FitErrors:  map[string][]string{"n2": {"node(s) didn't match node selector"}},
+		NodeScores: map[string]float64{"n1": 10},
+	}
+	if !reflect.DeepEqual(expectedTask, e1.Tasks["p1"]) {
+		t.Errorf("expected task explanation %v, got %v", expectedTask, e1.Tasks["p1"])
+	}
+
+	e2, found := GetJobExplanation("c1", "pg2")
+	if !found {
+		t.Fatalf("expected explanation of c1/pg2")
+	}
+	expectedValid := &PluginVerdict{Plugin: "gang", Reason: "NotEnoughPods", Message: "0/1 pods"}
+	if !reflect.DeepEqual(expectedValid, e2.JobValid) {
+		t.Errorf("expected JobValid verdict %v, got %v", expectedValid, e2.JobValid)
+	}
+
+	if _, found := GetJobExplanation("c1", "pg3"); found {
+		t.Errorf("expected no explanation of c1/pg3")
+	}
+}
+
+func TestNodeScoresLimited(t *testing.T) {
+	p1 := util.BuildPod("c1", "p1", "", v1.PodPending, util.BuildResourceList("1", "1G"), "pg1", nil, nil)
+	pg1 := buildJob("pg1", v1alpha1.PodGroupInqueue, p1)
+	task := pg1.Tasks[api.TaskID(p1.UID)]
+	task.Job = pg1.UID
+
+	// 15 nodes n00..n14 whose score is their index, n14 and n13 have the same score.
+	nodeScores := map[float64][]*api.NodeInfo{}
+	for i := 0; i < 15; i++ {
+		node := api.NewNodeInfo(util.BuildNode(fmt.Sprintf("n%02d", i), util.BuildResourceList("2", "2G"), nil))
+		score := float64(i)
+		if i == 14 {
+			score = 13
+		}
+		nodeScores[score] = append(nodeScores[score], node)
+	}
+
+	ssn := &Session{
+		Jobs:          map[api.JobID]*api.JobInfo{pg1.UID: pg1},
+		explanations:  map[api.JobID]*JobExplanation{},
+		queueOverused: map[api.QueueID]*PluginVerdict{},
+	}
+	ssn.RecordNodeScores(task, nodeScores)
+
+	expected := map[string]float64{}
+	for i := 5; i < 15; i++ {
+		expected[fmt.Sprintf("n%02d", i)] = float64(i)
+	}
+	expected["n14"] = 13
+	if got := ssn.explain(pg1).Tasks["p1"].NodeScores; !reflect.DeepEqual(expected, got) {
+		t.Errorf("expected node scores %v, got %v", expected, got)
+	}
+}
+
+func TestExplanationsDisabled(t *testing.T) {
+	p1 := util.BuildPod("c1", "p1", "", v1.PodPending, util.BuildResourceList("1", "1G"), "pg1", nil, nil)
+	pg1 := buildJob("pg1", v1alpha1.PodGroupInqueue, p1)
+	task := pg1.Tasks[api.TaskID(p1.UID)]
+	task.Job = pg1.UID
+	n1 := api.NewNodeInfo(util.BuildNode("n1", util.BuildResourceList("2", "2G"), nil))
+
+	// The explanations of the last session are dropped once disabled.
+	EnableExplanations(false)
+	if _, found := GetJobExplanation("c1", "pg1"); found {
+		t.Errorf("expected explanations to be dropped")
+	}
+	if isExplanationsEnabled() {
+		t.Fatalf("expected explanations to be disabled")
+	}
+
+	// The session is not explained, as opened while explanations are disabled.
+	ssn := &Session{
+		Jobs: map[api.JobID]*api.JobInfo{pg1.UID: pg1},
+		Tiers: []conf.Tier{
+			{
+				Plugins: []conf.PluginOption{
+					{Name: "proportion"},
+				},
+			},
+		},
+		jobEnqueueableFns: map[string]api.ValidateFn{
+			"proportion": func(obj interface{}) bool {
+				return false
+			},
+		},
+		queueOverused: map[api.QueueID]*PluginVerdict{},
+	}
+
+	ssn.JobEnqueueable(pg1)
+	ssn.RecordNodeScores(task, map[float64][]*api.NodeInfo{10: {n1}})
+	ssn.publishExplanations()
+
+	if ssn.explaining() {
+		t.Errorf("expected no explanations in session")
+	}
+	if _, found := GetJobExplanation("c1", "pg1"); found {
+		t.Errorf("expected no explanation of c1/pg1")
+	}
+}
@@ -21,8 +21,10 @@ type Action interface {
 	// The unique name of Action.
 	Name() string
 
-	// Initialize initializes the allocator plugins.
-	Initialize()
+	// Initialize initializes the action with its arguments in the configurations
+	// of scheduler; it's called again with the new arguments when the scheduler
+	// configuration is reloaded.
+	Initialize(arguments Arguments)
 
 	// Execute allocates the cluster's resources into each queue.
 	Execute(ssn *Session)
@@ -16,7 +16,10 @@ limitations under the License.
 
 package framework
 
-import "sync"
+import (
+	"sort"
+	"sync"
+)
 
 var pluginMutex sync.Mutex
 
@@ -26,6 +29,9 @@ type PluginBuilder func(Arguments) Plugin
 // Plugin management
 var pluginBuilders = map[string]PluginBuilder{}
 
+// The argument keys accepted by plugins
+var pluginArguments = map[string][]string{}
+
 // RegisterPluginBuilder register the plugin
 func RegisterPluginBuilder(name string, pc PluginBuilder) {
 	pluginMutex.Lock()
@@ -34,7 +40,8 @@ func RegisterPluginBuilder(name string, pc PluginBuilder) {
 	pluginBuilders[name] = pc
 }
 
-// CleanupPluginBuilders cleans up all the plugin
+// CleanupPluginBuilders cleans up all the plugin; the argument keys declared by
+// plugins when their packages are initialized are kept.
 func CleanupPluginBuilders() {
 	pluginMutex.Lock()
 	defer pluginMutex.Unlock()
@@ -51,9 +58,44 @@ func GetPluginBuilder(name string) (PluginBuilder, bool) {
 	return pb, found
 }
 
+// RegisterPluginArguments declares the argument keys accepted by the plugin; a key
+// ending with "." accepts all the keys with that prefix. The arguments of plugins
+// which did not declare their keys are not validated.
+func RegisterPluginArguments(name string, keys ...string) {
+	pluginMutex.Lock()
+	defer pluginMutex.Unlock()
+
+	pluginArguments[name] = append([]string{}, keys...)
+}
+
+// GetPluginArguments get the argument keys accepted by the plugin
+func GetPluginArguments(name string) ([]string, bool) {
+	pluginMutex.Lock()
+	defer pluginMutex.Unlock()
+
+	keys, found := pluginArguments[name]
+	return keys, found
+}
+
+// GetPluginNames get the names of all the registered plugins
+func GetPluginNames() []string {
+	pluginMutex.Lock()
+	defer pluginMutex.Unlock()
+
+	var names []string
+	for name := range pluginBuilders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 // Action management
 var actionMap = map[string]Action{}
 
+// actionArguments are the argument keys accepted by actions
+var actionArguments = map[string][]string{}
+
 // RegisterAction register action
 func RegisterAction(act Action) {
 	pluginMutex.Lock()
@@ -70,3 +112,35 @@ func GetAction(name string) (Action, bool) {
 	act, found := actionMap[name]
 	return act, found
 }
+
+// RegisterActionArguments declares the argument keys accepted by the action; a key
+// ending with "." accepts all the keys with that prefix. The arguments of actions
+// which did not declare their keys are not validated.
+func RegisterActionArguments(name string, keys ...string) {
+	pluginMutex.Lock()
+	defer pluginMutex.Unlock()
+
+	actionArguments[name] = append([]string{}, keys...)
+}
+
+// GetActionArguments get the argument keys accepted by the action
+func GetActionArguments(name string) ([]string, bool) {
+	pluginMutex.Lock()
+	defer pluginMutex.Unlock()
+
+	keys, found := actionArguments[name]
+	return keys, found
+}
+
+// GetActionNames get the names of all the registered actions
+func GetActionNames() []string {
+	pluginMutex.Lock()
+	defer pluginMutex.Unlock()
+
+	var names []string
+	for name := range actionMap {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,121 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package framework
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/golang/glog"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/client-go/util/workqueue"
+
+	"github.com/kubernetes-sigs/kube-batch/pkg/apis/scheduling/v1alpha1"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/api"
+)
+
+const (
+	queueUpdaterWorker = 16
+)
+
+type queueUpdater struct {
+	ssn        *Session
+	queueQueue []*api.QueueInfo
+	queueJobs  map[api.QueueID][]*api.JobInfo
+}
+
+func newQueueUpdater(ssn *Session) *queueUpdater {
+	queue := make([]*api.QueueInfo, 0, len(ssn.Queues))
+	for _, q := range ssn.Queues {
+		queue = append(queue, q)
+	}
+
+	queueJobs := map[api.QueueID][]*api.JobInfo{}
+	for _, job := range ssn.Jobs {
+		queueJobs[job.Queue] = append(queueJobs[job.Queue], job)
+	}
+
+	qu := &queueUpdater{
+		ssn:        ssn,
+		queueQueue: queue,
+		queueJobs:  queueJobs,
+	}
+	return qu
+}
+
+func (qu *queueUpdater) UpdateAll() {
+	workqueue.ParallelizeUntil(context.TODO(), queueUpdaterWorker, len(qu.queueQueue), qu.updateQueue)
+}
+
+// queueStatus returns the status of queue according to the jobs in it.
+func (qu *queueUpdater) queueStatus(queue *api.QueueInfo) v1alpha1.QueueStatus {
+	status := v1alpha1.QueueStatus{
+		State: queue.State,
+	}
+
+	allocated := api.EmptyResource()
+	for _, job := range qu.queueJobs[queue.UID] {
+		if job.PodGroup != nil {
+			switch job.PodGroup.Status.Phase {
+			case v1alpha1.PodGroupPending:
+				status.Pending++
+			case v1alpha1.PodGroupInqueue:
+				status.Inqueue++
+			case v1alpha1.PodGroupRunning:
+				status.Running++
+			case v1alpha1.PodGroupUnknown:
+				status.Unknown++
+			}
+		}
+
+		for s, tasks := range job.TaskStatusIndex {
+			if api.AllocatedStatus(s) {
+				for _, t := range tasks {
+					allocated.Add(t.Resreq)
+				}
+			}
+		}
+	}
+	status.Allocated = allocated.ResourceList()
+
+	if queue.Deserved != nil {
+		status.Deserved = queue.Deserved.ResourceList()
+		status.Share = fmt.Sprintf("%0.2f", queue.Share)
+	}
+
+	return status
+}
+
+// updateQueue update specified queue
+func (qu *queueUpdater) updateQueue(index int) {
+	queue := qu.queueQueue[index]
+
+	status := qu.queueStatus(queue)
+	if equality.Semantic.DeepEqual(status, queue.Queue.Status) {
+		return
+	}
+
+	// The Queue object is shared with cache, so update a copy of it.
+	newQueue := queue.Queue.DeepCopy()
+	newQueue.Status = status
+	queue.Queue = newQueue
+
+	if err := qu.ssn.cache.UpdateQueueStatus(queue); err != nil {
+		glog.Errorf("Failed to update status of Queue <%s>: %v", queue.Name, err)
+	}
+}
@@ -0,0 +1,95 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package framework
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kubernetes-sigs/kube-batch/pkg/apis/scheduling/v1alpha1"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/api"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/util"
+)
+
+func buildJob(name string, phase v1alpha1.PodGroupPhase, pods ...*v1.Pod) *api.JobInfo {
+	var tasks []*api.TaskInfo
+	for _, pod := range pods {
+		tasks = append(tasks, api.NewTaskInfo(pod))
+	}
+
+	job := api.NewJobInfo(api.JobID(name), tasks...)
+	job.SetPodGroup(&v1alpha1.PodGroup{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "c1",
+		},
+		Spec: v1alpha1.PodGroupSpec{
+			Queue: "q1",
+		},
+		Status: v1alpha1.PodGroupStatus{
+			Phase: phase,
+		},
+	})
+
+	return job
+}
+
+func TestQueueStatus(t *testing.T) {
+	queue := api.NewQueueInfo(&v1alpha1.Queue{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "q1",
+		},
+	})
+	queue.State = v1alpha1.QueueStateOpen
+	queue.Deserved = api.NewResource(util.BuildResourceList("4", "4G"))
+	queue.Share = 0.5
+
+	jobs := []*api.JobInfo{
+		buildJob("pg1", v1alpha1.PodGroupPending,
+			util.BuildPod("c1", "p1", "", v1.PodPending, util.BuildResourceList("1", "1G"), "pg1", nil, nil)),
+		buildJob("pg2", v1alpha1.PodGroupInqueue),
+		buildJob("pg3", v1alpha1.PodGroupRunning,
+			util.BuildPod("c1", "p2", "n1", v1.PodRunning, util.BuildResourceList("1", "1G"), "pg3", nil, nil),
+			util.BuildPod("c1", "p3", "n1", v1.PodRunning, util.BuildResourceList("1", "1G"), "pg3", nil, nil)),
+	}
+
+	ssn := &Session{
+		Jobs:   map[api.JobID]*api.JobInfo{},
+		Queues: map[api.QueueID]*api.QueueInfo{queue.UID: queue},
+	}
+	for _, job := range jobs {
+		ssn.Jobs[job.UID] = job
+	}
+
+	expected := v1alpha1.QueueStatus{
+		State:     v1alpha1.QueueStateOpen,
+		Pending:   1,
+		Inqueue:   1,
+		Running:   1,
+		Allocated: api.NewResource(util.BuildResourceList("2", "2G")).ResourceList(),
+		Deserved:  api.NewResource(util.BuildResourceList("4", "4G")).ResourceList(),
+		Share:     "0.50",
+	}
+
+	status := newQueueUpdater(ssn).queueStatus(queue)
+	if !equality.Semantic.DeepEqual(expected, status) {
+		t.Errorf("expected queue status: %v, got %v", expected, status)
+	}
+}
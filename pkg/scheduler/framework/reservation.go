@@ -0,0 +1,329 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package framework
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/golang/glog"
+
+	"github.com/kubernetes-sigs/kube-batch/pkg/apis/scheduling/v1alpha1"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/api"
+)
+
+// Reservation is the nodes reserved for a starving or blocked job; the tasks of other
+// jobs are not placed onto them unless the tasks finish before the job starts or the
+// reservation expires, so the resources released on them are left for the job.
+type Reservation struct {
+	Job   api.JobID
+	Nodes map[string]bool
+	// Until is the time when the reservation expires, zero if it does not expire.
+	Until time.Time
+	// Start is the time when the job is expected to start on the nodes, zero if
+	// unknown.
+	Start time.Time
+}
+
+// deadline returns the time before which the tasks of other jobs have to finish
+// on the reserved nodes: the expected start of the job if known and earlier than
+// the expiry of reservation.
+func (r *Reservation) deadline() time.Time {
+	if !r.Start.IsZero() && (r.Until.IsZero() || r.Start.Before(r.Until)) {
+		return r.Start
+	}
+	return r.Until
+}
+
+// CheckReservation returns an error if the node is reserved for a job other than the
+// job of task, and the task is not expected to finish before the reserved job starts
+// or the reservation expires.
+func (ssn *Session) CheckReservation(task *api.TaskInfo, node *api.NodeInfo) error {
+	r := ssn.Reservation
+	if r == nil || task.Job == r.Job || !r.Nodes[node.Name] {
+		return nil
+	}
+
+	deadline := r.deadline()
+	if runtime, found := ssn.TaskRuntime(task); found && !time.Now().Add(runtime).After(deadline) {
+		return nil
+	}
+
+	return fmt.Errorf("node <%s> is reserved for job <%s> until %v",
+		node.Name, r.Job, deadline.Format(time.RFC3339))
+}
+
+// StarvingJob returns the starving job of the highest order which is not ready and
+// not excluded, or nil if no such job is starving.
+func (ssn *Session) StarvingJob(excluded map[api.JobID]bool) *api.JobInfo {
+	var starving *api.JobInfo
+	for _, job := range ssn.Jobs {
+		if job.PodGroup.Status.Phase == v1alpha1.PodGroupPending || excluded[job.UID] {
+			continue
+		}
+		if ssn.JobReady(job) || !ssn.JobStarving(job) {
+			continue
+		}
+		if starving == nil || ssn.JobOrderFn(job, starving) {
+			starving = job
+		}
+	}
+
+	return starving
+}
+
+// Reserve reserves the nodes needed by the starving job of the highest order which is
+// not excluded, or returns nil if no such job is starving; the expiry of reservation
+// is set by the caller.
+func (ssn *Session) Reserve(excluded map[api.JobID]bool) *Reservation {
+	job := ssn.StarvingJob(excluded)
+	if job == nil {
+		return nil
+	}
+
+	reservation := &Reservation{
+		Job:   job.UID,
+		Nodes: ssn.neededNodes(job),
+	}
+	if start, _, found := ssn.ReservedStart(job); found && start.After(time.Now()) {
+		reservation.Start = start
+	}
+	glog.V(3).Infof("Reserved <%d> nodes for starving Job <%s/%s>.",
+		len(reservation.Nodes), job.Namespace, job.Name)
+
+	return reservation
+}
+
+// neededNodes returns the nodes on which the pending tasks needed by the job to be
+// ready fit once the tasks on them finish. The nodes with more idle and releasing
+// resources are taken first, as they are expected to be free sooner.
+func (ssn *Session) neededNodes(job *api.JobInfo) map[string]bool {
+	tasks := ssn.neededTasks(job)
+
+	var nodes []*api.NodeInfo
+	for _, node := range ssn.Nodes {
+		nodes = append(nodes, node)
+	}
+	sort.Slice(nodes, func(i, j int) bool {
+		li, lj := freeRatio(nodes[i]), freeRatio(nodes[j])
+		if li != lj {
+			return li > lj
+		}
+		return nodes[i].Name < nodes[j].Name
+	})
+
+	capacity := map[string]*api.Resource{}
+	for _, node := range nodes {
+		capacity[node.Name] = node.Allocatable.Clone()
+	}
+
+	reserved := map[string]bool{}
+	for _, task := range tasks {
+		for _, node := range nodes {
+			if !task.InitResreq.LessEqual(capacity[node.Name]) {
+				continue
+			}
+			if err := ssn.PredicateFn(task, node); err != nil {
+				continue
+			}
+			capacity[node.Name].Sub(task.InitResreq)
+			reserved[node.Name] = true
+			break
+		}
+	}
+
+	return reserved
+}
+
+// neededTasks returns the pending tasks of job in order which are needed by the job
+// to be ready.
+func (ssn *Session) neededTasks(job *api.JobInfo) []*api.TaskInfo {
+	var tasks []*api.TaskInfo
+	for _, task := range job.TaskStatusIndex[api.Pending] {
+		tasks = append(tasks, task)
+	}
+	sort.Slice(tasks, func(i, j int) bool {
+		return ssn.TaskOrderFn(tasks[i], tasks[j])
+	})
+	if needed := int(job.MinAvailable - job.ReadyTaskNum()); needed < len(tasks) {
+		tasks = tasks[:needed]
+	}
+
+	return tasks
+}
+
+// BlockedJob returns the job of the highest order which is not ready and has pending
+// tasks not placed by the former actions, or nil if no job is blocked.
+func (ssn *Session) BlockedJob() *api.JobInfo {
+	var blocked *api.JobInfo
+	for _, job := range ssn.Jobs {
+		if job.PodGroup.Status.Phase == v1alpha1.PodGroupPending {
+			continue
+		}
+		if vr := ssn.JobValid(job); vr != nil && !vr.Pass {
+			continue
+		}
+		if ssn.JobReady(job) || len(job.TaskStatusIndex[api.Pending]) == 0 {
+			continue
+		}
+		if blocked == nil || ssn.JobOrderFn(job, blocked) {
+			blocked = job
+		}
+	}
+
+	return blocked
+}
+
+// ReserveBlockedJob reserves the nodes on which the blocked job of the highest order
+// is expected to start, until it starts; it returns nil if no job is blocked or the
+// start of the job is unknown.
+func (ssn *Session) ReserveBlockedJob() *Reservation {
+	job := ssn.BlockedJob()
+	if job == nil {
+		return nil
+	}
+
+	start, nodes, found := ssn.ReservedStart(job)
+	if !found {
+		glog.V(3).Infof("The start of blocked Job <%s/%s> is unknown, skip reservation.",
+			job.Namespace, job.Name)
+		return nil
+	}
+	// The job fits now but is not placed, e.g. its queue is overused; reserving the
+	// nodes until now would keep all the other jobs off them.
+	if !start.After(time.Now()) {
+		glog.V(3).Infof("Blocked Job <%s/%s> fits now, skip reservation.", job.Namespace, job.Name)
+		return nil
+	}
+
+	glog.V(3).Infof("Reserved <%d> nodes for blocked Job <%s/%s> expected to start at %v.",
+		len(nodes), job.Namespace, job.Name, start)
+
+	return &Reservation{
+		Job:   job.UID,
+		Nodes: nodes,
+		Start: start,
+	}
+}
+
+// ReservedStart returns the earliest time when the pending tasks needed by the job
+// to be ready fit onto the nodes, and the nodes they fit onto. The resources of the
+// running tasks are expected to be released when the tasks finish by their runtime;
+// it returns false if the job does not fit by then.
+func (ssn *Session) ReservedStart(job *api.JobInfo) (time.Time, map[string]bool, bool) {
+	now := time.Now()
+
+	var nodes []*api.NodeInfo
+	free := map[string]*api.Resource{}
+	type release struct {
+		at       time.Time
+		node     string
+		resource *api.Resource
+	}
+	var releases []release
+	for _, node := range ssn.Nodes {
+		nodes = append(nodes, node)
+		free[node.Name] = node.Idle.Clone().Add(node.Releasing)
+
+		for _, task := range node.Tasks {
+			if task.Job == job.UID || !api.AllocatedStatus(task.Status) {
+				continue
+			}
+			runtime, found := ssn.TaskRuntime(task)
+			if !found {
+				continue
+			}
+			at := now.Add(runtime - runningTime(task, now))
+			if at.Before(now) {
+				at = now
+			}
+			releases = append(releases, release{at: at, node: node.Name, resource: task.Resreq})
+		}
+	}
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].Name < nodes[j].Name
+	})
+	sort.Slice(releases, func(i, j int) bool {
+		return releases[i].at.Before(releases[j].at)
+	})
+
+	tasks := ssn.neededTasks(job)
+	fit := func() map[string]bool {
+		capacity := map[string]*api.Resource{}
+		for name, resource := range free {
+			capacity[name] = resource.Clone()
+		}
+
+		placed := map[string]bool{}
+		for _, task := range tasks {
+			fitted := false
+			for _, node := range nodes {
+				if !task.InitResreq.LessEqual(capacity[node.Name]) {
+					continue
+				}
+				if err := ssn.PredicateFn(task, node); err != nil {
+					continue
+				}
+				capacity[node.Name].Sub(task.InitResreq)
+				placed[node.Name] = true
+				fitted = true
+				break
+			}
+			if !fitted {
+				return nil
+			}
+		}
+		return placed
+	}
+
+	if placed := fit(); placed != nil {
+		return now, placed, true
+	}
+	for i, r := range releases {
+		free[r.node].Add(r.resource)
+		// The tasks finishing at the same time are released together.
+		if i+1 < len(releases) && releases[i+1].at.Equal(r.at) {
+			continue
+		}
+		if placed := fit(); placed != nil {
+			return r.at, placed, true
+		}
+	}
+
+	return time.Time{}, nil, false
+}
+
+// freeRatio returns the smallest ratio of the idle and releasing resources to the
+// allocatable resources of node in cpu and memory.
+func freeRatio(node *api.NodeInfo) float64 {
+	free := node.Idle.Clone().Add(node.Releasing)
+
+	ratio := 1.0
+	if node.Allocatable.MilliCPU > 0 {
+		if r := free.MilliCPU / node.Allocatable.MilliCPU; r < ratio {
+			ratio = r
+		}
+	}
+	if node.Allocatable.Memory > 0 {
+		if r := free.Memory / node.Allocatable.Memory; r < ratio {
+			ratio = r
+		}
+	}
+
+	return ratio
+}
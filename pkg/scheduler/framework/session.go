@@ -22,6 +22,7 @@ import (
 	"github.com/golang/glog"
 
 	v1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1beta1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/uuid"
@@ -44,8 +45,12 @@ type Session struct {
 	Jobs    map[api.JobID]*api.JobInfo
 	Nodes   map[string]*api.NodeInfo
 	Queues  map[api.QueueID]*api.QueueInfo
+	PDBs    []*policyv1.PodDisruptionBudget
 	Backlog []*api.JobInfo
 	Tiers   []conf.Tier
+	// Reservation is the nodes locked for the starving job in the session, e.g.
+	// by reserve action; nil if no nodes are locked.
+	Reservation *Reservation
 
 	plugins           map[string]Plugin
 	eventHandlers     []*EventHandler
@@ -62,8 +67,18 @@ type Session struct {
 	overusedFns       map[string]api.ValidateFn
 	jobReadyFns       map[string]api.ValidateFn
 	jobPipelinedFns   map[string]api.ValidateFn
+	jobStarvingFns    map[string]api.ValidateFn
+	taskRuntimeFns    map[string]api.RuntimeFn
 	jobValidFns       map[string]api.ValidateExFn
 	jobEnqueueableFns map[string]api.ValidateFn
+
+	// eCache caches the predicate results of equivalent tasks in the session.
+	eCache *equivalenceCache
+
+	// explanations explain the scheduling decisions of jobs in the session; nil
+	// if explanations are not enabled.
+	explanations  map[api.JobID]*JobExplanation
+	queueOverused map[api.QueueID]*PluginVerdict
 }
 
 func openSession(cache cache.Cache) *Session {
@@ -91,10 +106,25 @@ func openSession(cache cache.Cache) *Session {
 		overusedFns:       map[string]api.ValidateFn{},
 		jobReadyFns:       map[string]api.ValidateFn{},
 		jobPipelinedFns:   map[string]api.ValidateFn{},
+		jobStarvingFns:    map[string]api.ValidateFn{},
+		taskRuntimeFns:    map[string]api.RuntimeFn{},
 		jobValidFns:       map[string]api.ValidateExFn{},
 		jobEnqueueableFns: map[string]api.ValidateFn{},
+
+		eCache: newEquivalenceCache(),
+
+		queueOverused: map[api.QueueID]*PluginVerdict{},
+	}
+	if isExplanationsEnabled() {
+		ssn.explanations = map[api.JobID]*JobExplanation{}
 	}
 
+	// Invalidates the cached predicate results on the node whose tasks are changed.
+	ssn.AddEventHandler(&EventHandler{
+		AllocateFunc:   ssn.eCache.onTaskUpdated,
+		DeallocateFunc: ssn.eCache.onTaskUpdated,
+	})
+
 	snapshot := cache.Snapshot()
 
 	ssn.Jobs = snapshot.Jobs
@@ -126,6 +156,7 @@ func openSession(cache cache.Cache) *Session {
 
 	ssn.Nodes = snapshot.Nodes
 	ssn.Queues = snapshot.Queues
+	ssn.PDBs = snapshot.PDBs
 
 	glog.V(3).Infof("Open Session %v with <%d> Job and <%d> Queues",
 		ssn.UID, len(ssn.Jobs), len(ssn.Queues))
@@ -137,11 +168,20 @@ func closeSession(ssn *Session) {
 	ju := newJobUpdater(ssn)
 	ju.UpdateAll()
 
+	qu := newQueueUpdater(ssn)
+	qu.UpdateAll()
+
+	ssn.publishExplanations()
+
 	ssn.Jobs = nil
 	ssn.Nodes = nil
+	ssn.PDBs = nil
 	ssn.Backlog = nil
 	ssn.plugins = nil
 	ssn.eventHandlers = nil
+	ssn.eCache = nil
+	ssn.explanations = nil
+	ssn.queueOverused = nil
 	ssn.jobOrderFns = nil
 	ssn.queueOrderFns = nil
 
@@ -362,6 +402,21 @@ func (ssn *Session) Evict(reclaimee *api.TaskInfo, reason string) error {
 	return nil
 }
 
+// evictable filters out the tasks whose eviction was rejected recently, e.g.
+// 429 (TooManyRequests) by PodDisruptionBudget, so they are not evicted again.
+func (ssn *Session) evictable(tasks []*api.TaskInfo) []*api.TaskInfo {
+	var evictable []*api.TaskInfo
+	for _, task := range tasks {
+		if ssn.cache.EvictionRejected(task) {
+			glog.V(3).Infof("Eviction of Task <%s/%s> was rejected, skip it in Session <%v>",
+				task.Namespace, task.Name, ssn.UID)
+			continue
+		}
+		evictable = append(evictable, task)
+	}
+	return evictable
+}
+
 // UpdateJobCondition update job condition accordingly.
 func (ssn *Session) UpdateJobCondition(jobInfo *api.JobInfo, cond *v1alpha1.PodGroupCondition) error {
 	job, ok := ssn.Jobs[jobInfo.UID]
@@ -17,6 +17,10 @@ limitations under the License.
 package framework
 
 import (
+	"time"
+
+	"github.com/golang/glog"
+
 	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/api"
 	schedulerapi "k8s.io/kubernetes/pkg/scheduler/api"
 )
@@ -56,6 +60,16 @@ func (ssn *Session) AddJobPipelinedFn(name string, vf api.ValidateFn) {
 	ssn.jobPipelinedFns[name] = vf
 }
 
+// AddJobStarvingFn add starving function
+func (ssn *Session) AddJobStarvingFn(name string, vf api.ValidateFn) {
+	ssn.jobStarvingFns[name] = vf
+}
+
+// AddTaskRuntimeFn add task runtime function
+func (ssn *Session) AddTaskRuntimeFn(name string, rf api.RuntimeFn) {
+	ssn.taskRuntimeFns[name] = rf
+}
+
 // AddPredicateFn add Predicate function
 func (ssn *Session) AddPredicateFn(name string, pf api.PredicateFn) {
 	ssn.predicateFns[name] = pf
@@ -101,6 +115,8 @@ func (ssn *Session) Reclaimable(reclaimer *api.TaskInfo, reclaimees []*api.TaskI
 	var victims []*api.TaskInfo
 	var init bool
 
+	reclaimees = ssn.evictable(reclaimees)
+
 	for _, tier := range ssn.Tiers {
 		for _, plugin := range tier.Plugins {
 			if !isEnabled(plugin.EnabledReclaimable) {
@@ -143,6 +159,8 @@ func (ssn *Session) Preemptable(preemptor *api.TaskInfo, preemptees []*api.TaskI
 	var victims []*api.TaskInfo
 	var init bool
 
+	preemptees = ssn.evictable(preemptees)
+
 	for _, tier := range ssn.Tiers {
 		for _, plugin := range tier.Plugins {
 			if !isEnabled(plugin.EnabledPreemptable) {
@@ -190,11 +208,13 @@ func (ssn *Session) Overused(queue *api.QueueInfo) bool {
 				continue
 			}
 			if of(queue) {
+				ssn.queueOverused[queue.UID] = &PluginVerdict{Plugin: plugin.Name}
 				return true
 			}
 		}
 	}
 
+	delete(ssn.queueOverused, queue.UID)
 	return false
 }
 
@@ -240,6 +260,52 @@ func (ssn *Session) JobPipelined(obj interface{}) bool {
 	return true
 }
 
+// JobStarving invoke starving function of the plugins; the job is starving if any
+// of the plugins says so.
+func (ssn *Session) JobStarving(obj interface{}) bool {
+	for _, tier := range ssn.Tiers {
+		for _, plugin := range tier.Plugins {
+			if !isEnabled(plugin.EnabledJobStarving) {
+				continue
+			}
+			jsf, found := ssn.jobStarvingFns[plugin.Name]
+			if !found {
+				continue
+			}
+
+			if jsf(obj) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// TaskRuntime invoke task runtime function of the plugins; the runtime given by
+// the first plugin which knows it is returned, otherwise the expected runtime
+// declared by the job of task.
+func (ssn *Session) TaskRuntime(task *api.TaskInfo) (time.Duration, bool) {
+	for _, tier := range ssn.Tiers {
+		for _, plugin := range tier.Plugins {
+			rf, found := ssn.taskRuntimeFns[plugin.Name]
+			if !found {
+				continue
+			}
+
+			if runtime, known := rf(task); known {
+				return runtime, true
+			}
+		}
+	}
+
+	if job, found := ssn.Jobs[task.Job]; found {
+		return job.ExpectedRuntime()
+	}
+
+	return 0, false
+}
+
 // JobValid invoke jobvalid function of the plugins
 func (ssn *Session) JobValid(obj interface{}) *api.ValidateResult {
 	for _, tier := range ssn.Tiers {
@@ -250,6 +316,13 @@ func (ssn *Session) JobValid(obj interface{}) *api.ValidateResult {
 			}
 
 			if vr := jrf(obj); vr != nil && !vr.Pass {
+				if job, ok := obj.(*api.JobInfo); ok && ssn.explaining() {
+					ssn.explain(job).JobValid = &PluginVerdict{
+						Plugin:  plugin.Name,
+						Reason:  vr.Reason,
+						Message: vr.Message,
+					}
+				}
 				return vr
 			}
 
@@ -269,6 +342,9 @@ func (ssn *Session) JobEnqueueable(obj interface{}) bool {
 			}
 
 			if res := fn(obj); !res {
+				if job, ok := obj.(*api.JobInfo); ok && ssn.explaining() {
+					ssn.explain(job).JobEnqueueable = &PluginVerdict{Plugin: plugin.Name}
+				}
 				return res
 			}
 		}
@@ -370,6 +446,24 @@ func (ssn *Session) TaskOrderFn(l, r interface{}) bool {
 
 // PredicateFn invoke predicate function of the plugins
 func (ssn *Session) PredicateFn(task *api.TaskInfo, node *api.NodeInfo) error {
+	if ssn.eCache == nil {
+		return ssn.predicateFn(task, node)
+	}
+
+	if found, err := ssn.eCache.lookup(task, node); found {
+		glog.V(4).Infof("Predicate result of Task <%s/%s> on Node <%s> is found in equivalence cache: %v",
+			task.Namespace, task.Name, node.Name, err)
+		return err
+	}
+
+	err := ssn.predicateFn(task, node)
+	ssn.eCache.update(task, node, err)
+
+	return err
+}
+
+// predicateFn invoke predicate function of the plugins without equivalence cache
+func (ssn *Session) predicateFn(task *api.TaskInfo, node *api.NodeInfo) error {
 	for _, tier := range ssn.Tiers {
 		for _, plugin := range tier.Plugins {
 			if !isEnabled(plugin.EnabledPredicate) {
@@ -17,6 +17,8 @@ limitations under the License.
 package framework
 
 import (
+	"fmt"
+
 	"github.com/golang/glog"
 
 	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/api"
@@ -68,8 +70,23 @@ func (s *Statement) Evict(reclaimee *api.TaskInfo, reason string) error {
 	return nil
 }
 
-func (s *Statement) evict(reclaimee *api.TaskInfo, reason string) error {
+// evict sends the eviction of reclaimee; if it fails, e.g. rejected by
+// PodDisruptionBudget, the following tasks pipelined onto the node of reclaimee are
+// unpipelined, as the resources they are pipelined onto are not released.
+func (s *Statement) evict(reclaimee *api.TaskInfo, reason string, following []operation) error {
 	if err := s.ssn.cache.Evict(reclaimee, reason); err != nil {
+		for i := range following {
+			op := &following[i]
+			if op.name != "pipeline" || op.args[1].(string) != reclaimee.NodeName {
+				continue
+			}
+			task := op.args[0].(*api.TaskInfo)
+			if e := s.unpipeline(task); e != nil {
+				glog.Errorf("Failed to unpipeline task <%v/%v>: %v.",
+					task.Namespace, task.Name, e)
+			}
+			op.name = "unpipelined"
+		}
 		if e := s.unevict(reclaimee, reason); err != nil {
 			glog.Errorf("Faled to unevict task <%v/%v>: %v.",
 				reclaimee.Namespace, reclaimee.Name, e)
@@ -93,9 +110,12 @@ func (s *Statement) unevict(reclaimee *api.TaskInfo, reason string) error {
 			reclaimee.Job, s.ssn.UID)
 	}
 
-	// Update task in node.
+	// Update task in node, which is kept on node with Releasing status by Evict.
 	if node, found := s.ssn.Nodes[reclaimee.NodeName]; found {
-		node.AddTask(reclaimee)
+		if err := node.UpdateTask(reclaimee); err != nil {
+			glog.Errorf("Failed to update task <%v/%v> in Session <%v>: %v",
+				reclaimee.Namespace, reclaimee.Name, s.ssn.UID, err)
+		}
 	}
 
 	for _, eh := range s.ssn.eventHandlers {
@@ -194,7 +214,121 @@ func (s *Statement) unpipeline(task *api.TaskInfo) error {
 	return nil
 }
 
-// Discard operation for evict and pipeline
+// Allocate the task to the node in the session, the binding is sent to
+// apiserver until the statement is committed.
+func (s *Statement) Allocate(task *api.TaskInfo, hostname string) error {
+	if err := s.ssn.cache.AllocateVolumes(task, hostname); err != nil {
+		return err
+	}
+
+	// Only update status in session
+	job, found := s.ssn.Jobs[task.Job]
+	if found {
+		if err := job.UpdateTaskStatus(task, api.Allocated); err != nil {
+			glog.Errorf("Failed to update task <%v/%v> status to %v in Session <%v>: %v",
+				task.Namespace, task.Name, api.Allocated, s.ssn.UID, err)
+			return err
+		}
+	} else {
+		glog.Errorf("Failed to found Job <%s> in Session <%s> index when binding.",
+			task.Job, s.ssn.UID)
+		return fmt.Errorf("failed to find job %s", task.Job)
+	}
+
+	task.NodeName = hostname
+
+	if node, found := s.ssn.Nodes[hostname]; found {
+		if err := node.AddTask(task); err != nil {
+			glog.Errorf("Failed to add task <%v/%v> to node <%v> in Session <%v>: %v",
+				task.Namespace, task.Name, hostname, s.ssn.UID, err)
+			return err
+		}
+		glog.V(3).Infof("After allocated Task <%v/%v> to Node <%v>: idle <%v>, used <%v>, releasing <%v>",
+			task.Namespace, task.Name, node.Name, node.Idle, node.Used, node.Releasing)
+	} else {
+		glog.Errorf("Failed to found Node <%s> in Session <%s> index when binding.",
+			hostname, s.ssn.UID)
+		return fmt.Errorf("failed to find node %s", hostname)
+	}
+
+	// Callbacks
+	for _, eh := range s.ssn.eventHandlers {
+		if eh.AllocateFunc != nil {
+			eh.AllocateFunc(&Event{
+				Task: task,
+			})
+		}
+	}
+
+	s.operations = append(s.operations, operation{
+		name: "allocate",
+		args: []interface{}{task, hostname},
+	})
+
+	return nil
+}
+
+func (s *Statement) allocate(task *api.TaskInfo) error {
+	if err := s.ssn.dispatch(task); err != nil {
+		glog.Errorf("Failed to dispatch task <%v/%v>: %v",
+			task.Namespace, task.Name, err)
+		if e := s.unallocate(task); e != nil {
+			glog.Errorf("Failed to unallocate task <%v/%v>: %v.",
+				task.Namespace, task.Name, e)
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (s *Statement) unallocate(task *api.TaskInfo) error {
+	// Update status in session
+	job, found := s.ssn.Jobs[task.Job]
+	if found {
+		if err := job.UpdateTaskStatus(task, api.Pending); err != nil {
+			glog.Errorf("Failed to update task <%v/%v> status to %v in Session <%v>: %v",
+				task.Namespace, task.Name, api.Pending, s.ssn.UID, err)
+		}
+	} else {
+		glog.Errorf("Failed to found Job <%s> in Session <%s> index when unallocating.",
+			task.Job, s.ssn.UID)
+	}
+
+	hostname := task.NodeName
+
+	if node, found := s.ssn.Nodes[hostname]; found {
+		if err := node.RemoveTask(task); err != nil {
+			glog.Errorf("Failed to remove task <%v/%v> from node <%v> in Session <%v>: %v",
+				task.Namespace, task.Name, hostname, s.ssn.UID, err)
+		}
+		glog.V(3).Infof("After unallocated Task <%v/%v> from Node <%v>: idle <%v>, used <%v>, releasing <%v>",
+			task.Namespace, task.Name, node.Name, node.Idle, node.Used, node.Releasing)
+	} else {
+		glog.Errorf("Failed to found Node <%s> in Session <%s> index when unallocating.",
+			hostname, s.ssn.UID)
+	}
+
+	for _, eh := range s.ssn.eventHandlers {
+		if eh.DeallocateFunc != nil {
+			eh.DeallocateFunc(&Event{
+				Task: task,
+			})
+		}
+	}
+
+	// Revert the volumes assumed by AllocateVolumes.
+	if err := s.ssn.cache.RevertVolumes(task); err != nil {
+		glog.Errorf("Failed to revert volumes of task <%v/%v> in Session <%v>: %v",
+			task.Namespace, task.Name, s.ssn.UID, err)
+	}
+
+	task.NodeName = ""
+
+	return nil
+}
+
+// Discard operation for evict, pipeline and allocate
 func (s *Statement) Discard() {
 	glog.V(3).Info("Discarding operations ...")
 	for i := len(s.operations) - 1; i >= 0; i-- {
@@ -204,19 +338,23 @@ func (s *Statement) Discard() {
 			s.unevict(op.args[0].(*api.TaskInfo), op.args[1].(string))
 		case "pipeline":
 			s.unpipeline(op.args[0].(*api.TaskInfo))
+		case "allocate":
+			s.unallocate(op.args[0].(*api.TaskInfo))
 		}
 	}
 }
 
-// Commit operation for evict and pipeline
+// Commit operation for evict, pipeline and allocate
 func (s *Statement) Commit() {
 	glog.V(3).Info("Committing operations ...")
-	for _, op := range s.operations {
+	for i, op := range s.operations {
 		switch op.name {
 		case "evict":
-			s.evict(op.args[0].(*api.TaskInfo), op.args[1].(string))
+			s.evict(op.args[0].(*api.TaskInfo), op.args[1].(string), s.operations[i+1:])
 		case "pipeline":
 			s.pipeline(op.args[0].(*api.TaskInfo))
+		case "allocate":
+			s.allocate(op.args[0].(*api.TaskInfo))
 		}
 	}
 }
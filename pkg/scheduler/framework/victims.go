@@ -0,0 +1,156 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package framework
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/api"
+)
+
+// VictimsCost is the cost of evicting a set of victims.
+type VictimsCost struct {
+	// BrokenGangs is the number of ready jobs which are not ready after eviction.
+	BrokenGangs int
+	// Priority is the total priority of victims.
+	Priority int64
+	// RunningTime is the total running time of victims, which is lost by eviction.
+	RunningTime time.Duration
+}
+
+// Less compares the costs by the number of broken gangs, then the total priority,
+// then the running time lost.
+func (c *VictimsCost) Less(r *VictimsCost) bool {
+	if c.BrokenGangs != r.BrokenGangs {
+		return c.BrokenGangs < r.BrokenGangs
+	}
+	if c.Priority != r.Priority {
+		return c.Priority < r.Priority
+	}
+	return c.RunningTime < r.RunningTime
+}
+
+func (c *VictimsCost) String() string {
+	return fmt.Sprintf("brokenGangs %d, priority %d, runningTime %v",
+		c.BrokenGangs, c.Priority, c.RunningTime)
+}
+
+func runningTime(task *api.TaskInfo, now time.Time) time.Duration {
+	if task.Pod == nil || task.Pod.Status.StartTime == nil {
+		return 0
+	}
+	return now.Sub(task.Pod.Status.StartTime.Time)
+}
+
+// breaksGang returns whether evicting the task alone makes its ready job not ready.
+func (ssn *Session) breaksGang(task *api.TaskInfo) bool {
+	job, found := ssn.Jobs[task.Job]
+	if !found || !job.Ready() {
+		return false
+	}
+	return job.ReadyTaskNum()-1 < job.MinAvailable
+}
+
+// SelectVictims selects the victims on the node whose eviction makes room for the
+// preemptor at the least cost; the victims which are not necessary are left out.
+// It returns nil if the preemptor does not fit the node even if all victims are
+// evicted.
+func (ssn *Session) SelectVictims(
+	preemptor *api.TaskInfo,
+	node *api.NodeInfo,
+	victims []*api.TaskInfo,
+) ([]*api.TaskInfo, *VictimsCost) {
+	resreq := preemptor.InitResreq
+	now := time.Now()
+
+	// The preemptor is pipelined on the resources released by victims.
+	available := node.Releasing.Clone()
+
+	candidates := make([]*api.TaskInfo, len(victims))
+	copy(candidates, victims)
+	sort.SliceStable(candidates, func(i, j int) bool {
+		l, r := candidates[i], candidates[j]
+		if lb, rb := ssn.breaksGang(l), ssn.breaksGang(r); lb != rb {
+			return !lb
+		}
+		if l.Priority != r.Priority {
+			return l.Priority < r.Priority
+		}
+		if lt, rt := runningTime(l, now), runningTime(r, now); lt != rt {
+			return lt < rt
+		}
+		return l.UID < r.UID
+	})
+
+	// Take the cheapest victims until the preemptor fits.
+	var selected []*api.TaskInfo
+	for _, victim := range candidates {
+		if resreq.LessEqual(available) {
+			break
+		}
+		selected = append(selected, victim)
+		available.Add(victim.Resreq)
+	}
+	if !resreq.LessEqual(available) {
+		return nil, nil
+	}
+
+	// Leave out the expensive victims which are not necessary for the preemptor.
+	for i := len(selected) - 1; i >= 0; i-- {
+		rest := available.Clone().Sub(selected[i].Resreq)
+		if resreq.LessEqual(rest) {
+			available = rest
+			selected = append(selected[:i], selected[i+1:]...)
+		}
+	}
+
+	return ssn.evaluateVictims(preemptor, node, selected, now)
+}
+
+// evaluateVictims computes the cost of evicting the victims by their resources and
+// jobs alone; the session is not changed, so no event handler is fired.
+func (ssn *Session) evaluateVictims(
+	preemptor *api.TaskInfo,
+	node *api.NodeInfo,
+	victims []*api.TaskInfo,
+	now time.Time,
+) ([]*api.TaskInfo, *VictimsCost) {
+	cost := &VictimsCost{}
+	released := node.Releasing.Clone()
+	evicted := map[api.JobID]int32{}
+	for _, victim := range victims {
+		if api.AllocatedStatus(victim.Status) {
+			evicted[victim.Job]++
+		}
+		released.Add(victim.Resreq)
+		cost.Priority += int64(victim.Priority)
+		cost.RunningTime += runningTime(victim, now)
+	}
+
+	for uid, num := range evicted {
+		if job, found := ssn.Jobs[uid]; found && job.Ready() && job.ReadyTaskNum()-num < job.MinAvailable {
+			cost.BrokenGangs++
+		}
+	}
+
+	if !preemptor.InitResreq.LessEqual(released) {
+		return nil, nil
+	}
+	return victims, cost
+}
@@ -0,0 +1,119 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package framework
+
+import (
+	"reflect"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kubernetes-sigs/kube-batch/pkg/apis/scheduling/v1alpha1"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/api"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/util"
+)
+
+func TestSelectVictims(t *testing.T) {
+	tests := []struct {
+		name     string
+		cpu      string
+		expected []api.TaskID
+		cost     *VictimsCost
+	}{
+		{
+			name:     "victim which does not break gang is selected",
+			cpu:      "1",
+			expected: []api.TaskID{"c1-p3"},
+			cost:     &VictimsCost{Priority: 1},
+		},
+		{
+			name:     "gang is broken if necessary",
+			cpu:      "2",
+			expected: []api.TaskID{"c1-p3", "c1-p4"},
+			cost:     &VictimsCost{BrokenGangs: 1, Priority: 2},
+		},
+		{
+			name: "preemptor does not fit node",
+			cpu:  "5",
+		},
+	}
+
+	for i, test := range tests {
+		node := api.NewNodeInfo(util.BuildNode("n1", util.BuildResourceList("4", "4G"), nil))
+		ssn := &Session{
+			Jobs:  map[api.JobID]*api.JobInfo{},
+			Nodes: map[string]*api.NodeInfo{node.Name: node},
+		}
+		for _, pg := range []struct {
+			name      string
+			minMember int32
+			pods      []string
+		}{
+			{"pg1", 2, []string{"p1", "p2"}},
+			{"pg2", 1, []string{"p3", "p4"}},
+		} {
+			var tasks []*api.TaskInfo
+			for _, name := range pg.pods {
+				task := api.NewTaskInfo(util.BuildPod("c1", name, "n1", v1.PodRunning,
+					util.BuildResourceList("1", "1G"), pg.name, nil, nil))
+				tasks = append(tasks, task)
+				node.AddTask(task)
+			}
+			job := api.NewJobInfo(api.JobID("c1/"+pg.name), tasks...)
+			job.SetPodGroup(&v1alpha1.PodGroup{
+				ObjectMeta: metav1.ObjectMeta{Name: pg.name, Namespace: "c1"},
+				Spec:       v1alpha1.PodGroupSpec{Queue: "q1", MinMember: pg.minMember},
+			})
+			ssn.Jobs[job.UID] = job
+		}
+
+		fired := 0
+		ssn.AddEventHandler(&EventHandler{
+			AllocateFunc:   func(event *Event) { fired++ },
+			DeallocateFunc: func(event *Event) { fired++ },
+		})
+
+		var candidates []*api.TaskInfo
+		for _, task := range node.Tasks {
+			candidates = append(candidates, task.Clone())
+		}
+		preemptor := api.NewTaskInfo(util.BuildPod("c1", "preemptor", "", v1.PodPending,
+			util.BuildResourceList(test.cpu, "1G"), "pg3", nil, nil))
+
+		victims, cost := ssn.SelectVictims(preemptor, node, candidates)
+
+		var selected []api.TaskID
+		for _, victim := range victims {
+			selected = append(selected, victim.UID)
+		}
+		if !reflect.DeepEqual(test.expected, selected) {
+			t.Errorf("case %d (%s): expected victims %v, got %v", i, test.name, test.expected, selected)
+		}
+		if !reflect.DeepEqual(test.cost, cost) {
+			t.Errorf("case %d (%s): expected cost %v, got %v", i, test.name, test.cost, cost)
+		}
+		if fired != 0 {
+			t.Errorf("case %d (%s): expected no event handler fired, got %d", i, test.name, fired)
+		}
+		for _, task := range node.Tasks {
+			if task.Status != api.Running {
+				t.Errorf("case %d (%s): expected task <%s> %v, got %v", i, test.name, task.UID, api.Running, task.Status)
+			}
+		}
+	}
+}
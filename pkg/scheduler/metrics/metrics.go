@@ -111,6 +111,39 @@ var (
 		},
 	)
 
+	predicateEvaluatedNodes = promauto.NewHistogram(
+		prometheus.HistogramOpts{
+			Subsystem: VolcanoNamespace,
+			Name:      "predicate_evaluated_nodes",
+			Help:      "Number of nodes evaluated by predicates for one task",
+			Buckets:   prometheus.ExponentialBuckets(1, 2, 15),
+		},
+	)
+
+	nodeSearchPercentage = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Subsystem: VolcanoNamespace,
+			Name:      "node_search_percentage",
+			Help:      "Percentage of nodes that once found feasible, the scheduler stops searching",
+		},
+	)
+
+	nodeSearchMinNodes = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Subsystem: VolcanoNamespace,
+			Name:      "node_search_min_nodes",
+			Help:      "Minimum number of feasible nodes to find for one task",
+		},
+	)
+
+	nodeSearchWorkers = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Subsystem: VolcanoNamespace,
+			Name:      "node_search_workers",
+			Help:      "Number of workers to predicate and prioritize nodes",
+		},
+	)
+
 	jobRetryCount = promauto.NewCounterVec(
 		prometheus.CounterOpts{
 			Subsystem: VolcanoNamespace,
@@ -165,6 +198,18 @@ func UpdateUnscheduleJobCount(jobCount int) {
 	unscheduleJobCount.Set(float64(jobCount))
 }
 
+// UpdatePredicateEvaluatedNodes records number of nodes evaluated by predicates for one task
+func UpdatePredicateEvaluatedNodes(nodeCount int) {
+	predicateEvaluatedNodes.Observe(float64(nodeCount))
+}
+
+// UpdateNodeSearchConfiguration records the configuration of searching nodes
+func UpdateNodeSearchConfiguration(percentage, minNodes int32, workers int) {
+	nodeSearchPercentage.Set(float64(percentage))
+	nodeSearchMinNodes.Set(float64(minNodes))
+	nodeSearchWorkers.Set(float64(workers))
+}
+
 // RegisterJobRetries total number of job retries.
 func RegisterJobRetries(jobID string) {
 	jobRetryCount.WithLabelValues(jobID).Inc()
@@ -0,0 +1,167 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aging
+
+import (
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/api"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/framework"
+)
+
+const (
+	// Rate is the key for the priority raised per minute of pending in YAML
+	Rate = "aging.rate"
+	// Cap is the key for the maximum priority raised by aging in YAML
+	Cap = "aging.cap"
+	// StarvationThreshold is the key for the pending time after which the job is
+	// starving in YAML, e.g. "30m"
+	StarvationThreshold = "aging.starvationThreshold"
+)
+
+const (
+	defaultRate                = 1
+	defaultCap                 = 100
+	defaultStarvationThreshold = time.Hour
+)
+
+// unready tracks when the jobs became unready across sessions, as the plugin is built
+// for each session.
+var unready = struct {
+	sync.Mutex
+	since map[api.JobID]time.Time
+}{since: map[api.JobID]time.Time{}}
+
+type agingPlugin struct {
+	rate                int
+	cap                 int
+	starvationThreshold time.Duration
+
+	// Arguments given for the plugin
+	pluginArguments framework.Arguments
+}
+
+func init() {
+	framework.RegisterPluginArguments("aging", Rate, Cap, StarvationThreshold)
+}
+
+// New return aging plugin
+func New(arguments framework.Arguments) framework.Plugin {
+	ap := &agingPlugin{
+		rate:                defaultRate,
+		cap:                 defaultCap,
+		starvationThreshold: defaultStarvationThreshold,
+		pluginArguments:     arguments,
+	}
+	arguments.GetInt(&ap.rate, Rate)
+	arguments.GetInt(&ap.cap, Cap)
+	arguments.GetDuration(&ap.starvationThreshold, StarvationThreshold)
+
+	return ap
+}
+
+func (ap *agingPlugin) Name() string {
+	return "aging"
+}
+
+// trackUnready records when the jobs of session became unready, and forgets the jobs
+// which are ready or deleted. A job first seen unready became unready when created if
+// none of its tasks started, otherwise it's taken as unready from now on, e.g. a job
+// which lost a pod, so it's not raised by the time it was running.
+func trackUnready(jobs map[api.JobID]*api.JobInfo, now time.Time) map[api.JobID]time.Time {
+	unready.Lock()
+	defer unready.Unlock()
+
+	for uid := range unready.since {
+		if job, found := jobs[uid]; !found || job.Ready() {
+			delete(unready.since, uid)
+		}
+	}
+
+	since := map[api.JobID]time.Time{}
+	for uid, job := range jobs {
+		if job.Ready() {
+			continue
+		}
+		if _, found := unready.since[uid]; !found {
+			if job.ReadyTaskNum() == 0 && !job.CreationTimestamp.IsZero() {
+				unready.since[uid] = job.CreationTimestamp.Time
+			} else {
+				unready.since[uid] = now
+			}
+		}
+		since[uid] = unready.since[uid]
+	}
+
+	return since
+}
+
+// pendingTime returns how long the job has been pending since it became unready; the
+// jobs which are ready are not pending.
+func pendingTime(job *api.JobInfo, since map[api.JobID]time.Time, now time.Time) time.Duration {
+	start, found := since[job.UID]
+	if job.Ready() || !found {
+		return 0
+	}
+	return now.Sub(start)
+}
+
+// priority returns the priority of job raised by the time it has been pending.
+func (ap *agingPlugin) priority(job *api.JobInfo, since map[api.JobID]time.Time, now time.Time) int64 {
+	raised := int64(pendingTime(job, since, now)/time.Minute) * int64(ap.rate)
+	if raised > int64(ap.cap) {
+		raised = int64(ap.cap)
+	}
+	return int64(job.Priority) + raised
+}
+
+func (ap *agingPlugin) OnSessionOpen(ssn *framework.Session) {
+	// All jobs are aged to the same time in the session, so the order is consistent.
+	now := time.Now()
+	since := trackUnready(ssn.Jobs, now)
+
+	jobOrderFn := func(l, r interface{}) int {
+		lv := l.(*api.JobInfo)
+		rv := r.(*api.JobInfo)
+
+		lp, rp := ap.priority(lv, since, now), ap.priority(rv, since, now)
+
+		glog.V(4).Infof("Aging JobOrderFn: <%v/%v> priority: %d, <%v/%v> priority: %d",
+			lv.Namespace, lv.Name, lp, rv.Namespace, rv.Name, rp)
+
+		if lp > rp {
+			return -1
+		}
+
+		if lp < rp {
+			return 1
+		}
+
+		return 0
+	}
+
+	ssn.AddJobOrderFn(ap.Name(), jobOrderFn)
+	ssn.AddJobStarvingFn(ap.Name(), func(obj interface{}) bool {
+		job := obj.(*api.JobInfo)
+		return !job.Ready() && pendingTime(job, since, now) >= ap.starvationThreshold
+	})
+}
+
+func (ap *agingPlugin) OnSessionClose(ssn *framework.Session) {}
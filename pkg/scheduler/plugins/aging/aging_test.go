@@ -0,0 +1,242 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aging
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	schedulingv1beta1 "k8s.io/api/scheduling/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	kbv1 "github.com/kubernetes-sigs/kube-batch/pkg/apis/scheduling/v1alpha1"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/api"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/cache"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/conf"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/framework"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/plugins/gang"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/util"
+)
+
+// buildCache builds a cache with two nodes partly used by pg2, the gang pg1 which
+// has been pending for two hours, and the job pg3 of higher priority just created.
+func buildCache() *cache.SchedulerCache {
+	schedulerCache := cache.NewOfflineCache("kube-batch", "q1")
+	schedulerCache.Evictor = &util.FakeEvictor{}
+	schedulerCache.StatusUpdater = &util.FakeStatusUpdater{}
+	schedulerCache.VolumeBinder = &util.FakeVolumeBinder{}
+	schedulerCache.Recorder = record.NewFakeRecorder(100)
+
+	for _, name := range []string{"n1", "n2"} {
+		schedulerCache.AddNode(util.BuildNode(name, util.BuildResourceList("2", "2G"), make(map[string]string)))
+	}
+	schedulerCache.AddQueue(&kbv1.Queue{
+		ObjectMeta: metav1.ObjectMeta{Name: "q1"},
+		Spec:       kbv1.QueueSpec{Weight: 1},
+	})
+	schedulerCache.AddPriorityClass(&schedulingv1beta1.PriorityClass{
+		ObjectMeta: metav1.ObjectMeta{Name: "high"},
+		Value:      50,
+	})
+
+	now := time.Now()
+	for _, pg := range []struct {
+		name          string
+		minMember     int32
+		priorityClass string
+		created       time.Time
+	}{
+		{"pg1", 2, "", now.Add(-2 * time.Hour)},
+		{"pg2", 1, "", now},
+		{"pg3", 1, "high", now},
+	} {
+		schedulerCache.AddPodGroup(&kbv1.PodGroup{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              pg.name,
+				Namespace:         "c1",
+				CreationTimestamp: metav1.NewTime(pg.created),
+			},
+			Spec: kbv1.PodGroupSpec{
+				Queue:             "q1",
+				MinMember:         pg.minMember,
+				PriorityClassName: pg.priorityClass,
+			},
+			Status: kbv1.PodGroupStatus{Phase: kbv1.PodGroupInqueue},
+		})
+	}
+
+	for _, p := range []struct{ name, node, cpu, pg string }{
+		{"p1", "", "2", "pg1"},
+		{"p2", "", "2", "pg1"},
+		{"p3", "n1", "2", "pg2"},
+		{"p4", "n2", "1", "pg2"},
+		{"p5", "", "2", "pg3"},
+	} {
+		phase := v1.PodPending
+		if len(p.node) != 0 {
+			phase = v1.PodRunning
+		}
+		schedulerCache.AddPod(util.BuildPod("c1", p.name, p.node, phase, util.BuildResourceList(p.cpu, "1G"),
+			p.pg, make(map[string]string), make(map[string]string)))
+	}
+
+	return schedulerCache
+}
+
+func openSession(schedulerCache *cache.SchedulerCache, arguments framework.Arguments) *framework.Session {
+	trueValue := true
+	return framework.OpenSession(schedulerCache, []conf.Tier{
+		{
+			Plugins: []conf.PluginOption{
+				{
+					Name:               "aging",
+					EnabledJobOrder:    &trueValue,
+					EnabledJobStarving: &trueValue,
+					Arguments:          arguments,
+				},
+				{
+					Name:            "gang",
+					EnabledJobReady: &trueValue,
+				},
+			},
+		},
+	})
+}
+
+func TestAgingJobOrder(t *testing.T) {
+	framework.RegisterPluginBuilder("aging", New)
+	framework.RegisterPluginBuilder("gang", gang.New)
+	defer framework.CleanupPluginBuilders()
+
+	tests := []struct {
+		name      string
+		arguments framework.Arguments
+		expected  api.JobID
+	}{
+		{
+			name:     "pending job is raised over job of higher priority",
+			expected: "c1/pg1",
+		},
+		{
+			name:      "raised priority is capped",
+			arguments: framework.Arguments{Cap: "10"},
+			expected:  "c1/pg3",
+		},
+		{
+			name:      "raised priority by rate",
+			arguments: framework.Arguments{Rate: "0"},
+			expected:  "c1/pg3",
+		},
+	}
+
+	for i, test := range tests {
+		ssn := openSession(buildCache(), test.arguments)
+
+		first := ssn.Jobs["c1/pg1"]
+		if ssn.JobOrderFn(ssn.Jobs["c1/pg3"], first) {
+			first = ssn.Jobs["c1/pg3"]
+		}
+		if first.UID != test.expected {
+			t.Errorf("case %d (%s): expected job <%s> first, got <%s>", i, test.name, test.expected, first.UID)
+		}
+
+		framework.CloseSession(ssn)
+	}
+}
+
+func TestAgingReservation(t *testing.T) {
+	framework.RegisterPluginBuilder("aging", New)
+	framework.RegisterPluginBuilder("gang", gang.New)
+	defer framework.CleanupPluginBuilders()
+
+	tests := []struct {
+		name      string
+		arguments framework.Arguments
+		expected  *framework.Reservation
+	}{
+		{
+			name: "nodes are reserved for starving job",
+			expected: &framework.Reservation{
+				Job:   "c1/pg1",
+				Nodes: map[string]bool{"n1": true, "n2": true},
+			},
+		},
+		{
+			name:      "no job is starving",
+			arguments: framework.Arguments{StarvationThreshold: "3h"},
+			expected:  nil,
+		},
+	}
+
+	for i, test := range tests {
+		ssn := openSession(buildCache(), test.arguments)
+
+		reservation := ssn.Reserve(nil)
+		if !reflect.DeepEqual(test.expected, reservation) {
+			t.Errorf("case %d (%s): expected reservation %v, got %v", i, test.name, test.expected, reservation)
+		}
+		if reservation != nil {
+			reservation.Until = time.Now().Add(time.Hour)
+		}
+		ssn.Reservation = reservation
+		if err := ssn.CheckReservation(ssn.Jobs["c1/pg1"].Tasks["c1-p1"], ssn.Nodes["n1"]); err != nil {
+			t.Errorf("case %d (%s): expected node <n1> available for task <c1/p1>, got %v", i, test.name, err)
+		}
+		err := ssn.CheckReservation(ssn.Jobs["c1/pg3"].Tasks["c1-p5"], ssn.Nodes["n1"])
+		if reserved := err != nil; reserved != (test.expected != nil) {
+			t.Errorf("case %d (%s): expected node <n1> reserved %t for task <c1/p5>, got %v",
+				i, test.name, test.expected != nil, err)
+		}
+
+		framework.CloseSession(ssn)
+	}
+}
+
+func TestAgingPendingTime(t *testing.T) {
+	framework.RegisterPluginBuilder("aging", New)
+	framework.RegisterPluginBuilder("gang", gang.New)
+	defer framework.CleanupPluginBuilders()
+
+	// pg4 was created two hours ago and ran, but lost one of its pods.
+	schedulerCache := buildCache()
+	schedulerCache.AddPodGroup(&kbv1.PodGroup{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "pg4",
+			Namespace:         "c1",
+			CreationTimestamp: metav1.NewTime(time.Now().Add(-2 * time.Hour)),
+		},
+		Spec:   kbv1.PodGroupSpec{Queue: "q1", MinMember: 2},
+		Status: kbv1.PodGroupStatus{Phase: kbv1.PodGroupRunning},
+	})
+	schedulerCache.AddPod(util.BuildPod("c1", "p6", "n2", v1.PodRunning, util.BuildResourceList("1", "1G"),
+		"pg4", make(map[string]string), make(map[string]string)))
+	schedulerCache.AddPod(util.BuildPod("c1", "p7", "", v1.PodPending, util.BuildResourceList("1", "1G"),
+		"pg4", make(map[string]string), make(map[string]string)))
+
+	ssn := openSession(schedulerCache, nil)
+	defer framework.CloseSession(ssn)
+
+	if !ssn.JobStarving(ssn.Jobs["c1/pg1"]) {
+		t.Errorf("expected job <c1/pg1> pending since its creation starving")
+	}
+	if ssn.JobStarving(ssn.Jobs["c1/pg4"]) {
+		t.Errorf("expected job <c1/pg4> which just became unready not starving")
+	}
+}
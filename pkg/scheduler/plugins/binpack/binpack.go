@@ -0,0 +1,190 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package binpack
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/golang/glog"
+
+	v1 "k8s.io/api/core/v1"
+	schedulerapi "k8s.io/kubernetes/pkg/scheduler/api"
+
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/api"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/framework"
+)
+
+const (
+	// BinpackWeight is the key for providing Binpack Priority Weight in YAML
+	BinpackWeight = "binpack.weight"
+	// BinpackCPU is the key for weight of cpu
+	BinpackCPU = "binpack.cpu"
+	// BinpackMemory is the key for weight of memory
+	BinpackMemory = "binpack.memory"
+	// BinpackResources is the key for additional resource key name
+	BinpackResources = "binpack.resources"
+	// BinpackResourcesPrefix is the key prefix for additional resource key name
+	BinpackResourcesPrefix = BinpackResources + "."
+)
+
+type priorityWeight struct {
+	binPackingWeight    int
+	binPackingResources map[v1.ResourceName]int
+}
+
+func (w *priorityWeight) String() string {
+	return fmt.Sprintf("binpack.weight(%d), resources(%v)", w.binPackingWeight, w.binPackingResources)
+}
+
+type binpackPlugin struct {
+	// Arguments given for the plugin
+	pluginArguments framework.Arguments
+	weight          priorityWeight
+}
+
+func init() {
+	// The weights of binpack, including the ones of scalar resources by prefix.
+	framework.RegisterPluginArguments("binpack", BinpackWeight, BinpackCPU,
+		BinpackMemory, BinpackResources, BinpackResourcesPrefix)
+}
+
+// New function returns binpackPlugin object
+func New(arguments framework.Arguments) framework.Plugin {
+	return &binpackPlugin{
+		pluginArguments: arguments,
+		weight:          calculateWeight(arguments),
+	}
+}
+
+func calculateWeight(args framework.Arguments) priorityWeight {
+	/*
+	   User should give priorityWeight in this format(binpack.weight, binpack.cpu, binpack.memory).
+	   Scalar resources, e.g. GPU, are given in binpack.resources, and the weight of each one
+	   is given in binpack.resources.<resource name>.
+
+	   actions: "enqueue, reclaim, allocate, backfill, preempt"
+	   tiers:
+	   - plugins:
+	     - name: binpack
+	       arguments:
+	         binpack.weight: 10
+	         binpack.cpu: 5
+	         binpack.memory: 1
+	         binpack.resources: nvidia.com/gpu, example.com/foo
+	         binpack.resources.nvidia.com/gpu: 2
+	         binpack.resources.example.com/foo: 3
+	*/
+
+	// Values are initialized to 1.
+	weight := priorityWeight{
+		binPackingWeight: 1,
+		binPackingResources: map[v1.ResourceName]int{
+			v1.ResourceCPU:    1,
+			v1.ResourceMemory: 1,
+		},
+	}
+
+	// Checks whether binpack.weight is provided or not, if given, modifies the value in weight struct.
+	args.GetInt(&weight.binPackingWeight, BinpackWeight)
+
+	// Checks whether binpack.cpu is provided or not, if given, modifies the value in weight struct.
+	cpu := weight.binPackingResources[v1.ResourceCPU]
+	args.GetInt(&cpu, BinpackCPU)
+	weight.binPackingResources[v1.ResourceCPU] = cpu
+
+	// Checks whether binpack.memory is provided or not, if given, modifies the value in weight struct.
+	memory := weight.binPackingResources[v1.ResourceMemory]
+	args.GetInt(&memory, BinpackMemory)
+	weight.binPackingResources[v1.ResourceMemory] = memory
+
+	// Checks the weight of each resource in binpack.resources, default to 1.
+	for _, resource := range strings.Split(args[BinpackResources], ",") {
+		resource = strings.TrimSpace(resource)
+		if len(resource) == 0 {
+			continue
+		}
+
+		resourceWeight := 1
+		args.GetInt(&resourceWeight, BinpackResourcesPrefix+resource)
+		weight.binPackingResources[v1.ResourceName(resource)] = resourceWeight
+	}
+
+	for resource, resourceWeight := range weight.binPackingResources {
+		if resourceWeight < 0 {
+			glog.Warningf("The weight of resource <%s> in binpack is negative, ignore it.", resource)
+			delete(weight.binPackingResources, resource)
+		}
+	}
+
+	return weight
+}
+
+func (bp *binpackPlugin) Name() string {
+	return "binpack"
+}
+
+func (bp *binpackPlugin) OnSessionOpen(ssn *framework.Session) {
+	glog.V(4).Infof("Enter binpack plugin with %v", &bp.weight)
+
+	if bp.weight.binPackingWeight == 0 {
+		glog.V(4).Infof("The weight of binpack is 0, skip it.")
+		return
+	}
+
+	nodeOrderFn := func(task *api.TaskInfo, node *api.NodeInfo) (float64, error) {
+		score := binPackingScore(task, node, bp.weight)
+
+		glog.V(4).Infof("Binpack score for task %s/%s on node %s is: %v", task.Namespace, task.Name, node.Name, score)
+		return score, nil
+	}
+	ssn.AddNodeOrderFn(bp.Name(), nodeOrderFn)
+}
+
+func (bp *binpackPlugin) OnSessionClose(ssn *framework.Session) {
+}
+
+// binPackingScore scores the node by its utilization after the task is placed on it;
+// the higher the utilization of the weighted resources, the higher the score.
+func binPackingScore(task *api.TaskInfo, node *api.NodeInfo, weight priorityWeight) float64 {
+	score := 0.0
+	weightSum := 0
+
+	for resource, resourceWeight := range weight.binPackingResources {
+		request := task.Resreq.Get(resource)
+		if request == 0 {
+			// Only the resources requested by the task are considered.
+			continue
+		}
+
+		allocatable := node.Allocatable.Get(resource)
+		used := node.Used.Get(resource)
+		if allocatable == 0 || request+used > allocatable {
+			// The node can not hold the task, it's up to predicates to filter it out.
+			return 0
+		}
+
+		score += (request + used) * float64(resourceWeight) / allocatable
+		weightSum += resourceWeight
+	}
+
+	if weightSum > 0 {
+		score /= float64(weightSum)
+	}
+
+	return score * float64(schedulerapi.MaxPriority) * float64(weight.binPackingWeight)
+}
@@ -0,0 +1,116 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package binpack
+
+import (
+	"reflect"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/api"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/framework"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/util"
+)
+
+func TestCalculateWeight(t *testing.T) {
+	tests := []struct {
+		name      string
+		arguments framework.Arguments
+		expected  priorityWeight
+	}{
+		{
+			name:      "default weight",
+			arguments: framework.Arguments{},
+			expected: priorityWeight{
+				binPackingWeight: 1,
+				binPackingResources: map[v1.ResourceName]int{
+					v1.ResourceCPU:    1,
+					v1.ResourceMemory: 1,
+				},
+			},
+		},
+		{
+			name: "weight of scalar resources",
+			arguments: framework.Arguments{
+				BinpackWeight:    "10",
+				BinpackCPU:       "5",
+				BinpackMemory:    "0",
+				BinpackResources: "nvidia.com/gpu, example.com/foo",
+				BinpackResourcesPrefix + "nvidia.com/gpu": "2",
+			},
+			expected: priorityWeight{
+				binPackingWeight: 10,
+				binPackingResources: map[v1.ResourceName]int{
+					v1.ResourceCPU:    5,
+					v1.ResourceMemory: 0,
+					"nvidia.com/gpu":  2,
+					"example.com/foo": 1,
+				},
+			},
+		},
+	}
+
+	for i, test := range tests {
+		weight := calculateWeight(test.arguments)
+		if !reflect.DeepEqual(weight, test.expected) {
+			t.Errorf("case %d (%s): expected: %v, got %v", i, test.name, &test.expected, &weight)
+		}
+	}
+}
+
+func TestBinPackingScore(t *testing.T) {
+	weight := calculateWeight(framework.Arguments{
+		BinpackResources: "nvidia.com/gpu",
+		BinpackResourcesPrefix + "nvidia.com/gpu": "2",
+	})
+
+	buildNode := func(name, gpu string, used ...*v1.Pod) *api.NodeInfo {
+		node := api.NewNodeInfo(util.BuildNode(name, util.BuildResourceListWithGPU("8", "8G", gpu), nil))
+		for _, pod := range used {
+			node.AddTask(api.NewTaskInfo(pod))
+		}
+		return node
+	}
+
+	task := api.NewTaskInfo(util.BuildPod("c1", "p1", "", v1.PodPending,
+		util.BuildResourceListWithGPU("2", "2G", "1"), "pg1", nil, nil))
+	running := util.BuildPod("c1", "p2", "n2", v1.PodRunning,
+		util.BuildResourceListWithGPU("4", "4G", "2"), "pg2", nil, nil)
+
+	empty := buildNode("n1", "4")
+	used := buildNode("n2", "4", running)
+	small := buildNode("n3", "1")
+
+	emptyScore := binPackingScore(task, empty, weight)
+	usedScore := binPackingScore(task, used, weight)
+	smallScore := binPackingScore(task, small, weight)
+
+	if usedScore <= emptyScore {
+		t.Errorf("expected the score of used node <%v> higher than empty node <%v>", usedScore, emptyScore)
+	}
+
+	// The gpu of small node is fully used after placement, which has higher weight.
+	if smallScore <= emptyScore {
+		t.Errorf("expected the score of small node <%v> higher than empty node <%v>", smallScore, emptyScore)
+	}
+
+	full := buildNode("n4", "0")
+	if score := binPackingScore(task, full, weight); score != 0 {
+		t.Errorf("expected the score of node without enough resource is 0, got %v", score)
+	}
+}
@@ -29,6 +29,11 @@ type conformancePlugin struct {
 	pluginArguments framework.Arguments
 }
 
+func init() {
+	// conformance plugin accepts no arguments.
+	framework.RegisterPluginArguments("conformance")
+}
+
 // New return conformance plugin
 func New(arguments framework.Arguments) framework.Plugin {
 	return &conformancePlugin{pluginArguments: arguments}
@@ -31,6 +31,9 @@ func ApplyPluginConfDefaults(option *conf.PluginOption) {
 	if option.EnabledJobPipelined == nil {
 		option.EnabledJobPipelined = &t
 	}
+	if option.EnabledJobStarving == nil {
+		option.EnabledJobStarving = &t
+	}
 	if option.EnabledTaskOrder == nil {
 		option.EnabledTaskOrder = &t
 	}
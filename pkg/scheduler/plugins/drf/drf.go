@@ -44,6 +44,11 @@ type drfPlugin struct {
 	pluginArguments framework.Arguments
 }
 
+func init() {
+	// drf plugin accepts no arguments.
+	framework.RegisterPluginArguments("drf")
+}
+
 // New return drf plugin
 func New(arguments framework.Arguments) framework.Plugin {
 	return &drfPlugin{
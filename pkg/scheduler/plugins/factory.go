@@ -19,10 +19,13 @@ package plugins
 import (
 	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/framework"
 
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/plugins/aging"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/plugins/binpack"
 	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/plugins/conformance"
 	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/plugins/drf"
 	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/plugins/gang"
 	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/plugins/nodeorder"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/plugins/pdb"
 	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/plugins/predicates"
 	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/plugins/priority"
 	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/plugins/proportion"
@@ -35,7 +38,10 @@ func init() {
 	framework.RegisterPluginBuilder("predicates", predicates.New)
 	framework.RegisterPluginBuilder("priority", priority.New)
 	framework.RegisterPluginBuilder("nodeorder", nodeorder.New)
+	framework.RegisterPluginBuilder("binpack", binpack.New)
 	framework.RegisterPluginBuilder("conformance", conformance.New)
+	framework.RegisterPluginBuilder("pdb", pdb.New)
+	framework.RegisterPluginBuilder("aging", aging.New)
 
 	// Plugins for Queues
 	framework.RegisterPluginBuilder("proportion", proportion.New)
@@ -30,11 +30,20 @@ import (
 	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/metrics"
 )
 
+const (
+	// WholeGangPreemption is the key for enabling the preemption of whole gangs in YAML
+	WholeGangPreemption = "gang.wholeGangPreemption"
+)
+
 type gangPlugin struct {
 	// Arguments given for the plugin
 	pluginArguments framework.Arguments
 }
 
+func init() {
+	framework.RegisterPluginArguments("gang", WholeGangPreemption)
+}
+
 // New return gang plugin
 func New(arguments framework.Arguments) framework.Plugin {
 	return &gangPlugin{pluginArguments: arguments}
@@ -68,14 +77,30 @@ func (gp *gangPlugin) OnSessionOpen(ssn *framework.Session) {
 
 	ssn.AddJobValidFn(gp.Name(), validJobFn)
 
+	wholeGangPreemption := false
+	gp.pluginArguments.GetBool(&wholeGangPreemption, WholeGangPreemption)
+
 	preemptableFn := func(preemptor *api.TaskInfo, preemptees []*api.TaskInfo) []*api.TaskInfo {
 		var victims []*api.TaskInfo
 
+		// The number of ready tasks of each job among preemptees.
+		readyPreemptees := map[api.JobID]int32{}
+		for _, preemptee := range preemptees {
+			if api.AllocatedStatus(preemptee.Status) {
+				readyPreemptees[preemptee.Job]++
+			}
+		}
+
 		for _, preemptee := range preemptees {
 			job := ssn.Jobs[preemptee.Job]
 			occupid := job.ReadyTaskNum()
 			preemptable := job.MinAvailable <= occupid-1 || job.MinAvailable == 1
 
+			// The gang is not broken if all of its ready tasks are evicted together.
+			if !preemptable && wholeGangPreemption && readyPreemptees[job.UID] >= occupid {
+				preemptable = true
+			}
+
 			if !preemptable {
 				glog.V(3).Infof("Can not preempt task <%v/%v> because of gang-scheduling",
 					preemptee.Namespace, preemptee.Name)
@@ -89,7 +114,6 @@ func (gp *gangPlugin) OnSessionOpen(ssn *framework.Session) {
 		return victims
 	}
 
-	// TODO(k82cn): Support preempt/reclaim batch job.
 	ssn.AddReclaimableFn(gp.Name(), preemptableFn)
 	ssn.AddPreemptableFn(gp.Name(), preemptableFn)
 
@@ -78,6 +78,12 @@ func (c *cachedNodeInfo) GetNodeInfo(name string) (*v1.Node, error) {
 }
 
 //New function returns prioritizePlugin object
+func init() {
+	// The weights of priorities which can be set in arguments.
+	framework.RegisterPluginArguments("nodeorder", NodeAffinityWeight,
+		PodAffinityWeight, LeastRequestedWeight, BalancedResourceWeight)
+}
+
 func New(aruguments framework.Arguments) framework.Plugin {
 	return &nodeOrderPlugin{pluginArguments: aruguments}
 }
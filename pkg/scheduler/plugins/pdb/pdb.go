@@ -0,0 +1,153 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdb
+
+import (
+	"github.com/golang/glog"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/api"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/framework"
+)
+
+type pdbPlugin struct {
+	// Arguments given for the plugin
+	pluginArguments framework.Arguments
+
+	budgets []*disruptionBudget
+}
+
+// disruptionBudget is the remaining disruptions of a PodDisruptionBudget in session.
+type disruptionBudget struct {
+	namespace string
+	name      string
+	selector  labels.Selector
+	allowed   int32
+}
+
+func init() {
+	// pdb plugin accepts no arguments.
+	framework.RegisterPluginArguments("pdb")
+}
+
+// New return pdb plugin
+func New(arguments framework.Arguments) framework.Plugin {
+	return &pdbPlugin{pluginArguments: arguments}
+}
+
+func (pp *pdbPlugin) Name() string {
+	return "pdb"
+}
+
+// matchedBudgets returns the budgets which select the task.
+func (pp *pdbPlugin) matchedBudgets(task *api.TaskInfo) []*disruptionBudget {
+	var budgets []*disruptionBudget
+	for _, budget := range pp.budgets {
+		if budget.namespace != task.Namespace || task.Pod == nil {
+			continue
+		}
+		if budget.selector.Matches(labels.Set(task.Pod.Labels)) {
+			budgets = append(budgets, budget)
+		}
+	}
+	return budgets
+}
+
+func (pp *pdbPlugin) OnSessionOpen(ssn *framework.Session) {
+	pp.budgets = nil
+	for _, pdb := range ssn.PDBs {
+		selector, err := metav1.LabelSelectorAsSelector(pdb.Spec.Selector)
+		if err != nil {
+			glog.Errorf("Failed to parse selector of PodDisruptionBudget <%s/%s>: %v",
+				pdb.Namespace, pdb.Name, err)
+			continue
+		}
+		// An empty selector matches nothing, the same as disruption controller.
+		if selector.Empty() {
+			continue
+		}
+
+		pp.budgets = append(pp.budgets, &disruptionBudget{
+			namespace: pdb.Namespace,
+			name:      pdb.Name,
+			selector:  selector,
+			allowed:   pdb.Status.PodDisruptionsAllowed,
+		})
+	}
+
+	evictableFn := func(evictor *api.TaskInfo, evictees []*api.TaskInfo) []*api.TaskInfo {
+		var victims []*api.TaskInfo
+
+		// The disruptions taken by the victims in this call.
+		taken := map[*disruptionBudget]int32{}
+		for _, evictee := range evictees {
+			budgets := pp.matchedBudgets(evictee)
+
+			evictable := true
+			for _, budget := range budgets {
+				if budget.allowed-taken[budget] <= 0 {
+					glog.V(3).Infof("Can not evict task <%v/%v> because of PodDisruptionBudget <%s/%s>",
+						evictee.Namespace, evictee.Name, budget.namespace, budget.name)
+					evictable = false
+					break
+				}
+			}
+			if !evictable {
+				continue
+			}
+
+			for _, budget := range budgets {
+				taken[budget]++
+			}
+			victims = append(victims, evictee)
+		}
+
+		glog.V(3).Infof("Victims from PDB plugins are %+v", victims)
+
+		return victims
+	}
+
+	ssn.AddPreemptableFn(pp.Name(), evictableFn)
+	ssn.AddReclaimableFn(pp.Name(), evictableFn)
+
+	// Count the disruptions of evicted tasks in session, which are given back
+	// if the eviction is discarded.
+	ssn.AddEventHandler(&framework.EventHandler{
+		AllocateFunc: func(event *framework.Event) {
+			if event.Task.Status != api.Running {
+				return
+			}
+			for _, budget := range pp.matchedBudgets(event.Task) {
+				budget.allowed++
+			}
+		},
+		DeallocateFunc: func(event *framework.Event) {
+			if event.Task.Status != api.Releasing {
+				return
+			}
+			for _, budget := range pp.matchedBudgets(event.Task) {
+				budget.allowed--
+			}
+		},
+	})
+}
+
+func (pp *pdbPlugin) OnSessionClose(ssn *framework.Session) {
+	pp.budgets = nil
+}
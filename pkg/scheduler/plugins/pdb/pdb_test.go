@@ -0,0 +1,241 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdb
+
+import (
+	"sort"
+	"sync"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	kbv1 "github.com/kubernetes-sigs/kube-batch/pkg/apis/scheduling/v1alpha1"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/api"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/cache"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/conf"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/framework"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/util"
+)
+
+// rejectingEvictor rejects all evictions with 429, as API server does if the
+// eviction violates PodDisruptionBudget.
+type rejectingEvictor struct {
+	sync.Mutex
+	evicts []string
+}
+
+func (re *rejectingEvictor) Evict(p *v1.Pod, gracePeriodSeconds *int64) error {
+	re.Lock()
+	defer re.Unlock()
+
+	re.evicts = append(re.evicts, p.Namespace+"/"+p.Name)
+	return apierrors.NewTooManyRequests("Cannot evict pod as it would violate the pod's disruption budget.", 0)
+}
+
+func buildPDB(name string, allowed int32) *policyv1.PodDisruptionBudget {
+	return &policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "c1",
+		},
+		Spec: policyv1.PodDisruptionBudgetSpec{
+			Selector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"app": "etcd"},
+			},
+		},
+		Status: policyv1.PodDisruptionBudgetStatus{
+			PodDisruptionsAllowed: allowed,
+		},
+	}
+}
+
+func buildCache(evictor cache.Evictor, pdbs ...*policyv1.PodDisruptionBudget) *cache.SchedulerCache {
+	schedulerCache := cache.NewOfflineCache("kube-batch", "q1")
+	schedulerCache.Evictor = evictor
+	schedulerCache.StatusUpdater = &util.FakeStatusUpdater{}
+	schedulerCache.VolumeBinder = &util.FakeVolumeBinder{}
+	schedulerCache.Recorder = record.NewFakeRecorder(100)
+	for _, pdb := range pdbs {
+		schedulerCache.PDBs[pdb.Namespace+"/"+pdb.Name] = pdb
+	}
+
+	schedulerCache.AddNode(util.BuildNode("n1", util.BuildResourceList("4", "4G"), make(map[string]string)))
+	schedulerCache.AddQueue(&kbv1.Queue{
+		ObjectMeta: metav1.ObjectMeta{Name: "q1"},
+		Spec:       kbv1.QueueSpec{Weight: 1},
+	})
+	schedulerCache.AddPodGroup(&kbv1.PodGroup{
+		ObjectMeta: metav1.ObjectMeta{Name: "pg1", Namespace: "c1"},
+		Spec:       kbv1.PodGroupSpec{Queue: "q1"},
+	})
+	for _, name := range []string{"p1", "p2"} {
+		schedulerCache.AddPod(util.BuildPod("c1", name, "n1", v1.PodRunning, util.BuildResourceList("1", "1G"),
+			"pg1", map[string]string{"app": "etcd"}, make(map[string]string)))
+	}
+	schedulerCache.AddPod(util.BuildPod("c1", "p3", "", v1.PodPending, util.BuildResourceList("1", "1G"),
+		"pg1", make(map[string]string), make(map[string]string)))
+
+	return schedulerCache
+}
+
+// tasks returns the running tasks sorted by name, and the pending task as preemptor.
+func tasks(ssn *framework.Session) ([]*api.TaskInfo, *api.TaskInfo) {
+	var running []*api.TaskInfo
+	var preemptor *api.TaskInfo
+	for _, job := range ssn.Jobs {
+		for _, task := range job.Tasks {
+			if task.Status == api.Running {
+				running = append(running, task)
+			} else {
+				preemptor = task
+			}
+		}
+	}
+	sort.Slice(running, func(i, j int) bool {
+		return running[i].Name < running[j].Name
+	})
+	return running, preemptor
+}
+
+func openSession(schedulerCache cache.Cache) *framework.Session {
+	trueValue := true
+	return framework.OpenSession(schedulerCache, []conf.Tier{
+		{
+			Plugins: []conf.PluginOption{
+				{
+					Name:               "pdb",
+					EnabledPreemptable: &trueValue,
+					EnabledReclaimable: &trueValue,
+				},
+			},
+		},
+	})
+}
+
+func TestDisruptionBudget(t *testing.T) {
+	framework.RegisterPluginBuilder("pdb", New)
+	defer framework.CleanupPluginBuilders()
+
+	tests := []struct {
+		name     string
+		pdbs     []*policyv1.PodDisruptionBudget
+		expected int
+	}{
+		{
+			name:     "no budget",
+			expected: 2,
+		},
+		{
+			name:     "no disruption allowed",
+			pdbs:     []*policyv1.PodDisruptionBudget{buildPDB("pdb1", 0)},
+			expected: 0,
+		},
+		{
+			name:     "one disruption allowed",
+			pdbs:     []*policyv1.PodDisruptionBudget{buildPDB("pdb1", 1)},
+			expected: 1,
+		},
+		{
+			name:     "the most restrictive budget wins",
+			pdbs:     []*policyv1.PodDisruptionBudget{buildPDB("pdb1", 2), buildPDB("pdb2", 1)},
+			expected: 1,
+		},
+	}
+
+	for i, test := range tests {
+		ssn := openSession(buildCache(&util.FakeEvictor{Channel: make(chan string, 2)}, test.pdbs...))
+
+		running, preemptor := tasks(ssn)
+		if victims := ssn.Preemptable(preemptor, running); len(victims) != test.expected {
+			t.Errorf("case %d (%s): expected %d preemptable victims, got %d",
+				i, test.name, test.expected, len(victims))
+		}
+		if victims := ssn.Reclaimable(preemptor, running); len(victims) != test.expected {
+			t.Errorf("case %d (%s): expected %d reclaimable victims, got %d",
+				i, test.name, test.expected, len(victims))
+		}
+
+		framework.CloseSession(ssn)
+	}
+}
+
+func TestDisruptionTakenInSession(t *testing.T) {
+	framework.RegisterPluginBuilder("pdb", New)
+	defer framework.CleanupPluginBuilders()
+
+	ssn := openSession(buildCache(&util.FakeEvictor{Channel: make(chan string, 2)}, buildPDB("pdb1", 1)))
+	defer framework.CloseSession(ssn)
+
+	running, preemptor := tasks(ssn)
+
+	stmt := ssn.Statement()
+	if err := stmt.Evict(running[0], "preempt"); err != nil {
+		t.Fatalf("failed to evict task %s: %v", running[0].Name, err)
+	}
+	// The only disruption is taken by p1, so p2 is blocked by the budget.
+	if victims := ssn.Preemptable(preemptor, running[1:]); len(victims) != 0 {
+		t.Errorf("expected no victims after the disruption is taken, got %d", len(victims))
+	}
+
+	// The disruption is given back after the eviction is discarded.
+	stmt.Discard()
+	if victims := ssn.Preemptable(preemptor, running[1:]); len(victims) != 1 {
+		t.Errorf("expected 1 victim after the eviction is discarded, got %d", len(victims))
+	}
+}
+
+func TestEvictionRejected(t *testing.T) {
+	framework.RegisterPluginBuilder("pdb", New)
+	defer framework.CleanupPluginBuilders()
+
+	evictor := &rejectingEvictor{}
+	schedulerCache := buildCache(evictor)
+	ssn := openSession(schedulerCache)
+	defer framework.CloseSession(ssn)
+
+	running, preemptor := tasks(ssn)
+	// The rejection is returned to the session, which keeps the task running.
+	if err := ssn.Evict(running[0], "preempt"); !apierrors.IsTooManyRequests(err) {
+		t.Fatalf("expected the eviction of %s to be rejected, got %v", running[0].Name, err)
+	}
+	if !schedulerCache.EvictionRejected(running[0]) {
+		t.Errorf("expected the eviction of %s to be recorded as rejected", running[0].Name)
+	}
+	if task := ssn.Jobs[running[0].Job].Tasks[running[0].UID]; task.Status != api.Running {
+		t.Errorf("expected task %s %v, got %v", running[0].Name, api.Running, task.Status)
+	}
+
+	// The rejected task is not a victim again in the same session.
+	victims := ssn.Preemptable(preemptor, running)
+	if len(victims) != 1 || victims[0].UID != running[1].UID {
+		t.Errorf("expected only %s to be preemptable, got %v", running[1].Name, victims)
+	}
+	victims = ssn.Reclaimable(preemptor, running)
+	if len(victims) != 1 || victims[0].UID != running[1].UID {
+		t.Errorf("expected only %s to be reclaimable, got %v", running[1].Name, victims)
+	}
+
+	evictor.Lock()
+	defer evictor.Unlock()
+	if len(evictor.evicts) != 1 {
+		t.Errorf("expected 1 eviction sent, got %v", evictor.evicts)
+	}
+}
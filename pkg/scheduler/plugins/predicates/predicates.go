@@ -45,6 +45,12 @@ type predicatesPlugin struct {
 	pluginArguments framework.Arguments
 }
 
+func init() {
+	// The predicates which can be enabled in arguments.
+	framework.RegisterPluginArguments("predicates", MemoryPressurePredicate,
+		DiskPressurePredicate, PIDPressurePredicate)
+}
+
 // New return predicate plugin
 func New(arguments framework.Arguments) framework.Plugin {
 	return &predicatesPlugin{pluginArguments: arguments}
@@ -27,6 +27,11 @@ type priorityPlugin struct {
 	pluginArguments framework.Arguments
 }
 
+func init() {
+	// priority plugin accepts no arguments.
+	framework.RegisterPluginArguments("priority")
+}
+
 // New return priority plugin
 func New(arguments framework.Arguments) framework.Plugin {
 	return &priorityPlugin{pluginArguments: arguments}
@@ -37,9 +37,19 @@ type queueAttr struct {
 	weight  int32
 	share   float64
 
+	// parent is nil for top-level queues
+	parent   *queueAttr
+	children []*queueAttr
+
 	deserved  *api.Resource
 	allocated *api.Resource
 	request   *api.Resource
+	guarantee *api.Resource
+}
+
+func init() {
+	// proportion plugin accepts no arguments.
+	framework.RegisterPluginArguments("proportion")
 }
 
 // New return proportion action
@@ -67,42 +77,236 @@ func (pp *proportionPlugin) OnSessionOpen(ssn *framework.Session) {
 	for _, job := range ssn.Jobs {
 		glog.V(4).Infof("Considering Job <%s/%s>.", job.Namespace, job.Name)
 
-		if _, found := pp.queueOpts[job.Queue]; !found {
-			queue := ssn.Queues[job.Queue]
-			attr := &queueAttr{
-				queueID: queue.UID,
-				name:    queue.Name,
-				weight:  queue.Weight,
-
-				deserved:  api.EmptyResource(),
-				allocated: api.EmptyResource(),
-				request:   api.EmptyResource(),
-			}
-			pp.queueOpts[job.Queue] = attr
-			glog.V(4).Infof("Added Queue <%s> attributes.", job.Queue)
-		}
+		attr := pp.buildQueueAttr(ssn, job.Queue)
 
+		// The resources of job are also accounted to the ancestors of its queue.
 		for status, tasks := range job.TaskStatusIndex {
 			if api.AllocatedStatus(status) {
 				for _, t := range tasks {
-					attr := pp.queueOpts[job.Queue]
-					attr.allocated.Add(t.Resreq)
-					attr.request.Add(t.Resreq)
+					for qa := attr; qa != nil; qa = qa.parent {
+						qa.allocated.Add(t.Resreq)
+						qa.request.Add(t.Resreq)
+					}
 				}
 			} else if status == api.Pending {
 				for _, t := range tasks {
-					attr := pp.queueOpts[job.Queue]
-					attr.request.Add(t.Resreq)
+					for qa := attr; qa != nil; qa = qa.parent {
+						qa.request.Add(t.Resreq)
+					}
+				}
+			}
+		}
+	}
+
+	// Calculates the deserved of top-level queues by total resource, and then
+	// the deserved of children queues by their parent's deserved recursively.
+	var roots []*queueAttr
+	for _, attr := range pp.queueOpts {
+		if attr.parent == nil {
+			roots = append(roots, attr)
+		}
+	}
+	pp.updateDeserved(pp.totalResource, roots)
+
+	ssn.AddQueueOrderFn(pp.Name(), func(l, r interface{}) int {
+		lv := l.(*api.QueueInfo)
+		rv := r.(*api.QueueInfo)
+
+		// Order queues by the share of their ancestors at the level they diverge.
+		lattr, rattr := siblingAncestors(pp.queueOpts[lv.UID], pp.queueOpts[rv.UID])
+
+		if lattr.share == rattr.share {
+			return 0
+		}
+
+		if lattr.share < rattr.share {
+			return -1
+		}
+
+		return 1
+	})
+
+	ssn.AddReclaimableFn(pp.Name(), func(reclaimer *api.TaskInfo, reclaimees []*api.TaskInfo) []*api.TaskInfo {
+		var victims []*api.TaskInfo
+		allocations := map[api.QueueID]*api.Resource{}
+
+		reclaimerAttr := pp.queueOpts[ssn.Jobs[reclaimer.Job].Queue]
+		for _, reclaimee := range reclaimees {
+			job := ssn.Jobs[reclaimee.Job]
+			// Reclaim resources at the level where the queues of reclaimer and reclaimee
+			// diverge, so the resources within a parent queue are shared by its children firstly.
+			_, attr := siblingAncestors(reclaimerAttr, pp.queueOpts[job.Queue])
+
+			// The queues from the queue of reclaimee up to the reclaimed one.
+			var path []*queueAttr
+			for qa := pp.queueOpts[job.Queue]; qa != attr.parent; qa = qa.parent {
+				if _, found := allocations[qa.queueID]; !found {
+					allocations[qa.queueID] = qa.allocated.Clone()
 				}
+				path = append(path, qa)
+			}
+
+			allocated := allocations[attr.queueID]
+			if allocated.Less(reclaimee.Resreq) {
+				glog.V(3).Infof("Failed to allocate resource for Task <%s/%s> in Queue <%s>, not enough resource.",
+					reclaimee.Namespace, reclaimee.Name, attr.name)
+				continue
+			}
+
+			if qa := underGuarantee(path, allocations, reclaimee.Resreq); qa != nil {
+				glog.V(3).Infof("Failed to reclaim Task <%s/%s>, Queue <%s> will be under its guarantee <%v>.",
+					reclaimee.Namespace, reclaimee.Name, qa.name, qa.guarantee)
+				continue
+			}
+
+			for _, qa := range path {
+				allocations[qa.queueID].Sub(reclaimee.Resreq)
 			}
+			if attr.deserved.LessEqual(allocated) {
+				victims = append(victims, reclaimee)
+			}
+		}
+
+		return victims
+	})
+
+	ssn.AddOverusedFn(pp.Name(), func(obj interface{}) bool {
+		queue := obj.(*api.QueueInfo)
+
+		// The queue is overused if itself or any of its ancestors is overused.
+		for attr := pp.queueOpts[queue.UID]; attr != nil; attr = attr.parent {
+			if attr.deserved.LessEqual(attr.allocated) {
+				glog.V(3).Infof("Queue <%v>: deserved <%v>, allocated <%v>, share <%v>",
+					attr.name, attr.deserved, attr.allocated, attr.share)
+				return true
+			}
+		}
+
+		return false
+	})
+
+	ssn.AddJobEnqueueableFn(pp.Name(), func(obj interface{}) bool {
+		job := obj.(*api.JobInfo)
+		pgResource := api.NewResource(*job.PodGroup.Spec.MinResources)
+
+		// The resource quota limit of the queue and its ancestors has not reached.
+		for attr := pp.queueOpts[job.Queue]; attr != nil; attr = attr.parent {
+			queue := ssn.Queues[attr.queueID]
+			if len(queue.Queue.Spec.Capability) == 0 {
+				glog.V(4).Infof("Capability of queue <%s> was not set, skip it for job <%s/%s>.",
+					queue.Name, job.Namespace, job.Name)
+				continue
+			}
+
+			if !pgResource.Clone().Add(attr.allocated).LessEqual(api.NewResource(queue.Queue.Spec.Capability)) {
+				glog.V(3).Infof("Capability of queue <%s> is reached, job <%s/%s> can not be Inqueue.",
+					queue.Name, job.Namespace, job.Name)
+				return false
+			}
+		}
+
+		return true
+	})
+
+	// Register event handlers.
+	ssn.AddEventHandler(&framework.EventHandler{
+		AllocateFunc: func(event *framework.Event) {
+			job := ssn.Jobs[event.Task.Job]
+			for attr := pp.queueOpts[job.Queue]; attr != nil; attr = attr.parent {
+				attr.allocated.Add(event.Task.Resreq)
+
+				pp.updateShare(attr)
+
+				glog.V(4).Infof("Proportion AllocateFunc: task <%v/%v>, resreq <%v>, queue <%v>, share <%v>",
+					event.Task.Namespace, event.Task.Name, event.Task.Resreq, attr.name, attr.share)
+			}
+		},
+		DeallocateFunc: func(event *framework.Event) {
+			job := ssn.Jobs[event.Task.Job]
+			for attr := pp.queueOpts[job.Queue]; attr != nil; attr = attr.parent {
+				attr.allocated.Sub(event.Task.Resreq)
+
+				pp.updateShare(attr)
+
+				glog.V(4).Infof("Proportion EvictFunc: task <%v/%v>, resreq <%v>, queue <%v>, share <%v>",
+					event.Task.Namespace, event.Task.Name, event.Task.Resreq, attr.name, attr.share)
+			}
+		},
+	})
+}
+
+// buildQueueAttr returns the attributes of queue, and builds the attributes
+// of the queue and its ancestors if not found.
+func (pp *proportionPlugin) buildQueueAttr(ssn *framework.Session, queueID api.QueueID) *queueAttr {
+	if attr, found := pp.queueOpts[queueID]; found {
+		return attr
+	}
+
+	queue := ssn.Queues[queueID]
+	attr := &queueAttr{
+		queueID: queue.UID,
+		name:    queue.Name,
+		weight:  queue.Weight,
+
+		deserved:  api.EmptyResource(),
+		allocated: api.EmptyResource(),
+		request:   api.EmptyResource(),
+		guarantee: api.NewResource(queue.Queue.Spec.Guarantee),
+	}
+	pp.queueOpts[queueID] = attr
+	glog.V(4).Infof("Added Queue <%s> attributes.", queueID)
+
+	if len(queue.Parent) == 0 {
+		return attr
+	}
+
+	if _, found := ssn.Queues[queue.Parent]; !found {
+		glog.Warningf("The parent <%s> of Queue <%s> does not exist, take it as top-level queue.",
+			queue.Parent, queue.Name)
+		return attr
+	}
+
+	if inCycle(ssn.Queues, queueID) {
+		glog.Warningf("The parent <%s> of Queue <%s> makes a cycle, take it as top-level queue.",
+			queue.Parent, queue.Name)
+		return attr
+	}
+
+	attr.parent = pp.buildQueueAttr(ssn, queue.Parent)
+	attr.parent.children = append(attr.parent.children, attr)
+
+	return attr
+}
+
+// updateDeserved divides total resource into the queues by weight, and then
+// divides the deserved resource of each queue into its children.
+func (pp *proportionPlugin) updateDeserved(total *api.Resource, attrs []*queueAttr) {
+	remaining := total.Clone()
+
+	// Reserves the guarantee of queues before dividing the rest by weight;
+	// the guarantee is not reserved more than the queue requests. If the
+	// guarantees are oversubscribed, they are scaled down proportionally so the
+	// result does not depend on the order of queues.
+	wanted := make([]*api.Resource, len(attrs))
+	totalWanted := api.EmptyResource()
+	for i, attr := range attrs {
+		wanted[i] = helpers.Min(attr.guarantee, attr.request)
+		totalWanted.Add(wanted[i])
+	}
+	for i, attr := range attrs {
+		reserved := helpers.Min(scaleDown(wanted[i], total, totalWanted), remaining)
+		if !attr.guarantee.LessEqual(reserved) {
+			glog.V(4).Infof("Reserved <%v> of the guarantee <%v> for queue <%s>, request <%v>, remaining <%v>.",
+				reserved, attr.guarantee, attr.name, attr.request, remaining)
 		}
+		attr.deserved.Add(reserved)
+		remaining.Sub(reserved)
 	}
 
-	remaining := pp.totalResource.Clone()
 	meet := map[api.QueueID]struct{}{}
 	for {
 		totalWeight := int32(0)
-		for _, attr := range pp.queueOpts {
+		for _, attr := range attrs {
 			if _, found := meet[attr.queueID]; found {
 				continue
 			}
@@ -120,7 +324,7 @@ func (pp *proportionPlugin) OnSessionOpen(ssn *framework.Session) {
 		// decreasedDeserved is the decreased value for attr.deserved of processed queues
 		increasedDeserved := api.EmptyResource()
 		decreasedDeserved := api.EmptyResource()
-		for _, attr := range pp.queueOpts {
+		for _, attr := range attrs {
 			glog.V(4).Infof("Considering Queue <%s>: weight <%d>, total weight <%d>.",
 				attr.name, attr.weight, totalWeight)
 			if _, found := meet[attr.queueID]; found {
@@ -153,111 +357,109 @@ func (pp *proportionPlugin) OnSessionOpen(ssn *framework.Session) {
 		}
 	}
 
-	ssn.AddQueueOrderFn(pp.Name(), func(l, r interface{}) int {
-		lv := l.(*api.QueueInfo)
-		rv := r.(*api.QueueInfo)
-
-		if pp.queueOpts[lv.UID].share == pp.queueOpts[rv.UID].share {
-			return 0
+	for _, attr := range attrs {
+		if len(attr.children) != 0 {
+			pp.updateDeserved(attr.deserved, attr.children)
 		}
+	}
+}
 
-		if pp.queueOpts[lv.UID].share < pp.queueOpts[rv.UID].share {
-			return -1
+// scaleDown scales the resource down by total/sum in each dimension where sum
+// exceeds total.
+func scaleDown(res, total, sum *api.Resource) *api.Resource {
+	scaled := res.Clone()
+	if sum.MilliCPU > total.MilliCPU {
+		scaled.MilliCPU = res.MilliCPU * total.MilliCPU / sum.MilliCPU
+	}
+	if sum.Memory > total.Memory {
+		scaled.Memory = res.Memory * total.Memory / sum.Memory
+	}
+	for name, quant := range res.ScalarResources {
+		totalQuant := total.ScalarResources[name]
+		if sumQuant := sum.ScalarResources[name]; sumQuant > totalQuant {
+			scaled.ScalarResources[name] = quant * totalQuant / sumQuant
 		}
+	}
+	return scaled
+}
 
-		return 1
-	})
-
-	ssn.AddReclaimableFn(pp.Name(), func(reclaimer *api.TaskInfo, reclaimees []*api.TaskInfo) []*api.TaskInfo {
-		var victims []*api.TaskInfo
-		allocations := map[api.QueueID]*api.Resource{}
-
-		for _, reclaimee := range reclaimees {
-			job := ssn.Jobs[reclaimee.Job]
-			attr := pp.queueOpts[job.Queue]
-
-			if _, found := allocations[job.Queue]; !found {
-				allocations[job.Queue] = attr.allocated.Clone()
-			}
-			allocated := allocations[job.Queue]
-			if allocated.Less(reclaimee.Resreq) {
-				glog.V(3).Infof("Failed to allocate resource for Task <%s/%s> in Queue <%s>, not enough resource.",
-					reclaimee.Namespace, reclaimee.Name, job.Queue)
-				continue
-			}
-
-			allocated.Sub(reclaimee.Resreq)
-			if attr.deserved.LessEqual(allocated) {
-				victims = append(victims, reclaimee)
-			}
+// underGuarantee returns the first queue in the path whose allocated resource
+// will be under its guarantee if the resource is reclaimed from it, or nil.
+func underGuarantee(path []*queueAttr, allocations map[api.QueueID]*api.Resource, resreq *api.Resource) *queueAttr {
+	for _, qa := range path {
+		allocated := allocations[qa.queueID]
+		if !resreq.LessEqual(allocated) {
+			return qa
 		}
 
-		return victims
-	})
-
-	ssn.AddOverusedFn(pp.Name(), func(obj interface{}) bool {
-		queue := obj.(*api.QueueInfo)
-		attr := pp.queueOpts[queue.UID]
-
-		overused := attr.deserved.LessEqual(attr.allocated)
-		if overused {
-			glog.V(3).Infof("Queue <%v>: deserved <%v>, allocated <%v>, share <%v>",
-				queue.Name, attr.deserved, attr.allocated, attr.share)
+		// Only the part of guarantee that has been allocated is kept.
+		kept := helpers.Min(qa.guarantee, allocated)
+		if !kept.LessEqual(allocated.Clone().Sub(resreq)) {
+			return qa
 		}
+	}
 
-		return overused
-	})
-
-	ssn.AddJobEnqueueableFn(pp.Name(), func(obj interface{}) bool {
-		job := obj.(*api.JobInfo)
-		queueID := job.Queue
-		attr := pp.queueOpts[queueID]
-		queue := ssn.Queues[queueID]
+	return nil
+}
 
-		// If no capability is set, always enqueue the job.
-		if len(queue.Queue.Spec.Capability) == 0 {
+// inCycle checks whether the queue is one of its ancestors.
+func inCycle(queues map[api.QueueID]*api.QueueInfo, queueID api.QueueID) bool {
+	visited := map[api.QueueID]struct{}{}
+	for id := queues[queueID].Parent; len(id) != 0; {
+		if id == queueID {
 			return true
 		}
-
-		pgResource := api.NewResource(*job.PodGroup.Spec.MinResources)
-		if len(queue.Queue.Spec.Capability) == 0 {
-			glog.V(4).Infof("Capability of queue <%s> was not set, allow job <%s/%s> to Inqueue.",
-				queue.Name, job.Namespace, job.Name)
-			return true
+		if _, found := visited[id]; found {
+			// The cycle does not include the queue itself.
+			return false
 		}
-		// The queue resource quota limit has not reached
-		if pgResource.Clone().Add(attr.allocated).LessEqual(api.NewResource(queue.Queue.Spec.Capability)) {
-			return true
+		visited[id] = struct{}{}
+
+		parent, found := queues[id]
+		if !found {
+			return false
 		}
-		return false
-	})
+		id = parent.Parent
+	}
 
-	// Register event handlers.
-	ssn.AddEventHandler(&framework.EventHandler{
-		AllocateFunc: func(event *framework.Event) {
-			job := ssn.Jobs[event.Task.Job]
-			attr := pp.queueOpts[job.Queue]
-			attr.allocated.Add(event.Task.Resreq)
+	return false
+}
 
-			pp.updateShare(attr)
+// siblingAncestors returns the ancestors of l and r (including themselves)
+// which are siblings, i.e. the children of their lowest common ancestor. If
+// one queue is the ancestor of the other, the two queues are returned.
+func siblingAncestors(l, r *queueAttr) (*queueAttr, *queueAttr) {
+	lpath := ancestors(l)
+	rpath := ancestors(r)
 
-			glog.V(4).Infof("Proportion AllocateFunc: task <%v/%v>, resreq <%v>,  share <%v>",
-				event.Task.Namespace, event.Task.Name, event.Task.Resreq, attr.share)
-		},
-		DeallocateFunc: func(event *framework.Event) {
-			job := ssn.Jobs[event.Task.Job]
-			attr := pp.queueOpts[job.Queue]
-			attr.allocated.Sub(event.Task.Resreq)
+	for i := 0; i < len(lpath) && i < len(rpath); i++ {
+		if lpath[i] != rpath[i] {
+			return lpath[i], rpath[i]
+		}
+	}
 
-			pp.updateShare(attr)
+	return l, r
+}
 
-			glog.V(4).Infof("Proportion EvictFunc: task <%v/%v>, resreq <%v>,  share <%v>",
-				event.Task.Namespace, event.Task.Name, event.Task.Resreq, attr.share)
-		},
-	})
+// ancestors returns the path from top-level queue to the queue.
+func ancestors(attr *queueAttr) []*queueAttr {
+	var path []*queueAttr
+	for ; attr != nil; attr = attr.parent {
+		path = append([]*queueAttr{attr}, path...)
+	}
+
+	return path
 }
 
 func (pp *proportionPlugin) OnSessionClose(ssn *framework.Session) {
+	// Records the deserved and share of queues for their status.
+	for _, attr := range pp.queueOpts {
+		if queue, found := ssn.Queues[attr.queueID]; found {
+			queue.Deserved = attr.deserved
+			queue.Share = attr.share
+		}
+	}
+
 	pp.totalResource = nil
 	pp.queueOpts = nil
 }
@@ -0,0 +1,297 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proportion
+
+import (
+	"fmt"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	kbv1 "github.com/kubernetes-sigs/kube-batch/pkg/apis/scheduling/v1alpha1"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/api"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/cache"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/conf"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/framework"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/util"
+)
+
+func buildQueue(name, parent string, weight int32) *kbv1.Queue {
+	return &kbv1.Queue{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+		Spec: kbv1.QueueSpec{
+			Weight: weight,
+			Parent: parent,
+		},
+	}
+}
+
+func buildQueueWithGuarantee(name string, weight int32, guarantee v1.ResourceList) *kbv1.Queue {
+	queue := buildQueue(name, "", weight)
+	queue.Spec.Guarantee = guarantee
+	return queue
+}
+
+func buildPodGroup(name, queue string) *kbv1.PodGroup {
+	return &kbv1.PodGroup{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "c1",
+		},
+		Spec: kbv1.PodGroupSpec{
+			Queue: queue,
+		},
+	}
+}
+
+func buildPendingPods(groupName string, num int) []*v1.Pod {
+	var pods []*v1.Pod
+	for i := 0; i < num; i++ {
+		pods = append(pods, util.BuildPod("c1", fmt.Sprintf("%s-p%d", groupName, i), "", v1.PodPending,
+			util.BuildResourceListWithGPU("1", "1G", "1"), groupName, make(map[string]string), make(map[string]string)))
+	}
+	return pods
+}
+
+func TestHierarchicalDeserved(t *testing.T) {
+	var plugin *proportionPlugin
+	framework.RegisterPluginBuilder("proportion", func(arguments framework.Arguments) framework.Plugin {
+		plugin = New(arguments).(*proportionPlugin)
+		return plugin
+	})
+	defer framework.CleanupPluginBuilders()
+
+	tests := []struct {
+		name      string
+		podGroups []*kbv1.PodGroup
+		pods      []*v1.Pod
+		queues    []*kbv1.Queue
+		expected  map[api.QueueID]float64
+	}{
+		{
+			name: "children share the deserved of parent by weight",
+			podGroups: []*kbv1.PodGroup{
+				buildPodGroup("pg1", "team1"),
+				buildPodGroup("pg2", "team2"),
+				buildPodGroup("pg3", "dept2"),
+			},
+			pods: append(append(buildPendingPods("pg1", 8), buildPendingPods("pg2", 8)...), buildPendingPods("pg3", 8)...),
+			queues: []*kbv1.Queue{
+				buildQueue("dept1", "", 1),
+				buildQueue("dept2", "", 1),
+				buildQueue("team1", "dept1", 1),
+				buildQueue("team2", "dept1", 3),
+			},
+			expected: map[api.QueueID]float64{
+				"dept1": 4000,
+				"dept2": 4000,
+				"team1": 1000,
+				"team2": 3000,
+			},
+		},
+		{
+			name: "idle resource of parent goes to siblings firstly",
+			podGroups: []*kbv1.PodGroup{
+				buildPodGroup("pg1", "team1"),
+				buildPodGroup("pg2", "team2"),
+				buildPodGroup("pg3", "dept2"),
+			},
+			pods: append(append(buildPendingPods("pg1", 8), buildPendingPods("pg2", 1)...), buildPendingPods("pg3", 8)...),
+			queues: []*kbv1.Queue{
+				buildQueue("dept1", "", 1),
+				buildQueue("dept2", "", 1),
+				buildQueue("team1", "dept1", 1),
+				buildQueue("team2", "dept1", 3),
+			},
+			expected: map[api.QueueID]float64{
+				"dept1": 4000,
+				"dept2": 4000,
+				"team1": 3000,
+				"team2": 1000,
+			},
+		},
+		{
+			name: "guarantee is reserved before dividing by weight",
+			podGroups: []*kbv1.PodGroup{
+				buildPodGroup("pg1", "q1"),
+				buildPodGroup("pg2", "q2"),
+			},
+			pods: append(buildPendingPods("pg1", 8), buildPendingPods("pg2", 8)...),
+			queues: []*kbv1.Queue{
+				buildQueueWithGuarantee("q1", 1, util.BuildResourceListWithGPU("6", "6G", "6")),
+				buildQueue("q2", "", 1),
+			},
+			expected: map[api.QueueID]float64{
+				"q1": 7000,
+				"q2": 1000,
+			},
+		},
+		{
+			name: "guarantee is not reserved more than request",
+			podGroups: []*kbv1.PodGroup{
+				buildPodGroup("pg1", "q1"),
+				buildPodGroup("pg2", "q2"),
+			},
+			pods: append(buildPendingPods("pg1", 2), buildPendingPods("pg2", 8)...),
+			queues: []*kbv1.Queue{
+				buildQueueWithGuarantee("q1", 1, util.BuildResourceListWithGPU("6", "6G", "6")),
+				buildQueue("q2", "", 1),
+			},
+			expected: map[api.QueueID]float64{
+				"q1": 2000,
+				"q2": 6000,
+			},
+		},
+		{
+			name: "oversubscribed guarantees are scaled down proportionally",
+			podGroups: []*kbv1.PodGroup{
+				buildPodGroup("pg1", "q1"),
+				buildPodGroup("pg2", "q2"),
+			},
+			pods: append(buildPendingPods("pg1", 10), buildPendingPods("pg2", 10)...),
+			queues: []*kbv1.Queue{
+				buildQueueWithGuarantee("q1", 1, util.BuildResourceListWithGPU("6", "6G", "6")),
+				buildQueueWithGuarantee("q2", 1, util.BuildResourceListWithGPU("10", "10G", "10")),
+			},
+			expected: map[api.QueueID]float64{
+				"q1": 3000,
+				"q2": 5000,
+			},
+		},
+		{
+			name: "queues in cycle are taken as top-level queues",
+			podGroups: []*kbv1.PodGroup{
+				buildPodGroup("pg1", "q1"),
+				buildPodGroup("pg2", "q2"),
+			},
+			pods: append(buildPendingPods("pg1", 8), buildPendingPods("pg2", 8)...),
+			queues: []*kbv1.Queue{
+				buildQueue("q1", "q2", 1),
+				buildQueue("q2", "q1", 1),
+			},
+			expected: map[api.QueueID]float64{
+				"q1": 4000,
+				"q2": 4000,
+			},
+		},
+	}
+
+	for i, test := range tests {
+		schedulerCache := &cache.SchedulerCache{
+			Nodes:         make(map[string]*api.NodeInfo),
+			Jobs:          make(map[api.JobID]*api.JobInfo),
+			Queues:        make(map[api.QueueID]*api.QueueInfo),
+			StatusUpdater: &util.FakeStatusUpdater{},
+			VolumeBinder:  &util.FakeVolumeBinder{},
+
+			Recorder: record.NewFakeRecorder(100),
+		}
+		schedulerCache.AddNode(util.BuildNode("n1", util.BuildResourceListWithGPU("8", "8G", "8"), make(map[string]string)))
+		for _, pod := range test.pods {
+			schedulerCache.AddPod(pod)
+		}
+		for _, pg := range test.podGroups {
+			schedulerCache.AddPodGroup(pg)
+		}
+		for _, q := range test.queues {
+			schedulerCache.AddQueue(q)
+		}
+
+		ssn := framework.OpenSession(schedulerCache, []conf.Tier{
+			{
+				Plugins: []conf.PluginOption{
+					{
+						Name: "proportion",
+					},
+				},
+			},
+		})
+
+		for queue, deserved := range test.expected {
+			attr, found := plugin.queueOpts[queue]
+			if !found {
+				t.Errorf("case %d (%s): failed to find attributes of queue <%s>", i, test.name, queue)
+				continue
+			}
+			if attr.deserved.MilliCPU != deserved {
+				t.Errorf("case %d (%s): expected deserved cpu of queue <%s>: %v, got %v",
+					i, test.name, queue, deserved, attr.deserved.MilliCPU)
+			}
+		}
+
+		framework.CloseSession(ssn)
+	}
+}
+
+func TestUnderGuarantee(t *testing.T) {
+	tests := []struct {
+		name      string
+		guarantee *api.Resource
+		allocated *api.Resource
+		resreq    *api.Resource
+		expected  bool
+	}{
+		{
+			name:      "no guarantee",
+			guarantee: api.EmptyResource(),
+			allocated: api.NewResource(util.BuildResourceList("2", "2G")),
+			resreq:    api.NewResource(util.BuildResourceList("1", "1G")),
+			expected:  false,
+		},
+		{
+			name:      "above guarantee after reclaimed",
+			guarantee: api.NewResource(util.BuildResourceList("1", "1G")),
+			allocated: api.NewResource(util.BuildResourceList("2", "2G")),
+			resreq:    api.NewResource(util.BuildResourceList("1", "1G")),
+			expected:  false,
+		},
+		{
+			name:      "under guarantee after reclaimed",
+			guarantee: api.NewResource(util.BuildResourceList("2", "1G")),
+			allocated: api.NewResource(util.BuildResourceList("2", "2G")),
+			resreq:    api.NewResource(util.BuildResourceList("1", "1G")),
+			expected:  true,
+		},
+		{
+			name:      "unallocated guarantee is not kept",
+			guarantee: api.NewResource(util.BuildResourceListWithGPU("1", "1G", "2")),
+			allocated: api.NewResource(util.BuildResourceList("2", "2G")),
+			resreq:    api.NewResource(util.BuildResourceList("1", "1G")),
+			expected:  false,
+		},
+	}
+
+	for i, test := range tests {
+		attr := &queueAttr{
+			queueID:   "q1",
+			name:      "q1",
+			guarantee: test.guarantee,
+		}
+		allocations := map[api.QueueID]*api.Resource{
+			attr.queueID: test.allocated,
+		}
+
+		got := underGuarantee([]*queueAttr{attr}, allocations, test.resreq) != nil
+		if got != test.expected {
+			t.Errorf("case %d (%s): expected: %v, got %v", i, test.name, test.expected, got)
+		}
+	}
+}
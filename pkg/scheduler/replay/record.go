@@ -0,0 +1,262 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package replay
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"time"
+
+	"github.com/golang/glog"
+
+	v1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1beta1"
+
+	kbv1 "github.com/kubernetes-sigs/kube-batch/pkg/apis/scheduling/v1alpha1"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/api"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/conf"
+)
+
+// SessionRecord is the record of a scheduling session: the snapshot of cache, the
+// scheduler configuration, the state of random node choices, and the decisions.
+type SessionRecord struct {
+	Timestamp time.Time `json:"timestamp"`
+
+	// Seed is the seed of random source which picks the best node among the nodes
+	// with same score.
+	Seed int64 `json:"seed"`
+	// NodeIndex is the index of node to start searching from.
+	NodeIndex int `json:"nodeIndex"`
+
+	Actions    []string                     `json:"actions"`
+	Tiers      []conf.Tier                  `json:"tiers"`
+	NodeSearch conf.NodeSearchConfiguration `json:"nodeSearch"`
+	// Configurations are the arguments of actions.
+	Configurations []conf.Configuration `json:"configurations,omitempty"`
+
+	Nodes  []NodeRecord  `json:"nodes"`
+	Queues []QueueRecord `json:"queues"`
+	Jobs   []JobRecord   `json:"jobs"`
+	// PDBs are the PodDisruptionBudgets which limit the evictions of session.
+	PDBs []*policyv1.PodDisruptionBudget `json:"pdbs,omitempty"`
+
+	Decisions Decisions `json:"decisions"`
+}
+
+// NodeRecord is the record of a node and the tasks on it.
+type NodeRecord struct {
+	Node  *v1.Node     `json:"node"`
+	Tasks []TaskRecord `json:"tasks,omitempty"`
+}
+
+// QueueRecord is the record of a queue and its state reported by cache.
+type QueueRecord struct {
+	Queue *kbv1.Queue     `json:"queue"`
+	State kbv1.QueueState `json:"state"`
+}
+
+// JobRecord is the record of a job and its tasks.
+type JobRecord struct {
+	UID       api.JobID   `json:"uid"`
+	Name      string      `json:"name"`
+	Namespace string      `json:"namespace"`
+	Queue     api.QueueID `json:"queue"`
+	Priority  int32       `json:"priority"`
+
+	PodGroup *kbv1.PodGroup                `json:"podGroup,omitempty"`
+	PDB      *policyv1.PodDisruptionBudget `json:"pdb,omitempty"`
+
+	Tasks []TaskRecord `json:"tasks,omitempty"`
+}
+
+// TaskRecord is the record of a task, whose status and node may be different
+// from its pod, e.g. Binding.
+type TaskRecord struct {
+	Job      api.JobID      `json:"job"`
+	Status   api.TaskStatus `json:"status"`
+	NodeName string         `json:"nodeName,omitempty"`
+	Pod      *v1.Pod        `json:"pod"`
+}
+
+// Decisions are the decisions of scheduling session in order.
+type Decisions struct {
+	Bindings  []Binding  `json:"bindings,omitempty"`
+	Evictions []Eviction `json:"evictions,omitempty"`
+}
+
+// Binding is the decision to bind a task to node.
+type Binding struct {
+	Task string `json:"task"`
+	Node string `json:"node"`
+}
+
+// Eviction is the decision to evict a task.
+type Eviction struct {
+	Task   string `json:"task"`
+	Reason string `json:"reason"`
+}
+
+func taskKey(task *api.TaskInfo) string {
+	return fmt.Sprintf("%s/%s", task.Namespace, task.Name)
+}
+
+// setSnapshot records the snapshot of cache; the objects are deep copied, so the
+// changes in session, e.g. the status of PodGroups, are not recorded.
+func (r *SessionRecord) setSnapshot(snapshot *api.ClusterInfo) {
+	r.Nodes, r.Queues, r.Jobs, r.PDBs = nil, nil, nil, nil
+
+	for _, node := range snapshot.Nodes {
+		nr := NodeRecord{Node: node.Node.DeepCopy()}
+		for _, task := range node.Tasks {
+			nr.Tasks = append(nr.Tasks, newTaskRecord(task))
+		}
+		sortTaskRecords(nr.Tasks)
+		r.Nodes = append(r.Nodes, nr)
+	}
+	sort.Slice(r.Nodes, func(i, j int) bool {
+		return r.Nodes[i].Node.Name < r.Nodes[j].Node.Name
+	})
+
+	for _, queue := range snapshot.Queues {
+		r.Queues = append(r.Queues, QueueRecord{
+			Queue: queue.Queue.DeepCopy(),
+			State: queue.State,
+		})
+	}
+	sort.Slice(r.Queues, func(i, j int) bool {
+		return r.Queues[i].Queue.Name < r.Queues[j].Queue.Name
+	})
+
+	for _, job := range snapshot.Jobs {
+		jr := JobRecord{
+			UID:       job.UID,
+			Name:      job.Name,
+			Namespace: job.Namespace,
+			Queue:     job.Queue,
+			Priority:  job.Priority,
+			PodGroup:  job.PodGroup.DeepCopy(),
+			PDB:       job.PDB.DeepCopy(),
+		}
+		for _, task := range job.Tasks {
+			jr.Tasks = append(jr.Tasks, newTaskRecord(task))
+		}
+		sortTaskRecords(jr.Tasks)
+		r.Jobs = append(r.Jobs, jr)
+	}
+	sort.Slice(r.Jobs, func(i, j int) bool {
+		return r.Jobs[i].UID < r.Jobs[j].UID
+	})
+
+	for _, pdb := range snapshot.PDBs {
+		r.PDBs = append(r.PDBs, pdb.DeepCopy())
+	}
+	sort.Slice(r.PDBs, func(i, j int) bool {
+		if r.PDBs[i].Namespace != r.PDBs[j].Namespace {
+			return r.PDBs[i].Namespace < r.PDBs[j].Namespace
+		}
+		return r.PDBs[i].Name < r.PDBs[j].Name
+	})
+}
+
+func newTaskRecord(task *api.TaskInfo) TaskRecord {
+	return TaskRecord{
+		Job:      task.Job,
+		Status:   task.Status,
+		NodeName: task.NodeName,
+		Pod:      task.Pod.DeepCopy(),
+	}
+}
+
+func sortTaskRecords(tasks []TaskRecord) {
+	sort.Slice(tasks, func(i, j int) bool {
+		return tasks[i].Pod.UID < tasks[j].Pod.UID
+	})
+}
+
+func (t *TaskRecord) taskInfo() *api.TaskInfo {
+	ti := api.NewTaskInfo(t.Pod)
+	ti.Job = t.Job
+	ti.Status = t.Status
+	ti.NodeName = t.NodeName
+	return ti
+}
+
+// ClusterInfo rebuilds the snapshot of cache from the record.
+func (r *SessionRecord) ClusterInfo() *api.ClusterInfo {
+	snapshot := &api.ClusterInfo{
+		Nodes:  make(map[string]*api.NodeInfo),
+		Jobs:   make(map[api.JobID]*api.JobInfo),
+		Queues: make(map[api.QueueID]*api.QueueInfo),
+	}
+
+	for _, nr := range r.Nodes {
+		node := api.NewNodeInfo(nr.Node)
+		for _, tr := range nr.Tasks {
+			if err := node.AddTask(tr.taskInfo()); err != nil {
+				glog.Errorf("Failed to add task to node <%s>: %v", node.Name, err)
+			}
+		}
+		snapshot.Nodes[node.Name] = node
+	}
+
+	for _, qr := range r.Queues {
+		queue := api.NewQueueInfo(qr.Queue)
+		queue.State = qr.State
+		snapshot.Queues[queue.UID] = queue
+	}
+
+	for _, jr := range r.Jobs {
+		job := api.NewJobInfo(jr.UID)
+		if jr.PodGroup != nil {
+			job.SetPodGroup(jr.PodGroup)
+		}
+		if jr.PDB != nil {
+			job.SetPDB(jr.PDB)
+		}
+		job.Name = jr.Name
+		job.Namespace = jr.Namespace
+		job.Queue = jr.Queue
+		job.Priority = jr.Priority
+		for _, tr := range jr.Tasks {
+			job.AddTaskInfo(tr.taskInfo())
+		}
+		snapshot.Jobs[job.UID] = job
+	}
+
+	for _, pdb := range r.PDBs {
+		snapshot.PDBs = append(snapshot.PDBs, pdb.DeepCopy())
+	}
+
+	return snapshot
+}
+
+// LoadSessionRecord loads the session record from file.
+func LoadSessionRecord(file string) (*SessionRecord, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	record := &SessionRecord{}
+	if err := json.Unmarshal(data, record); err != nil {
+		return nil, fmt.Errorf("failed to load session record <%s>: %v", file, err)
+	}
+
+	return record, nil
+}
@@ -0,0 +1,234 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package replay
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/api"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/cache"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/conf"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/framework"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/util"
+)
+
+const (
+	recordFilePrefix = "session-"
+	recordFileSuffix = ".json"
+
+	// recordQueueSize is the number of records waiting to be saved; the records
+	// are dropped if the writer falls behind, so scheduling is never blocked.
+	recordQueueSize = 16
+)
+
+// Recorder records the scheduling sessions into a local directory, only the
+// latest maxRecords records are kept. The records are saved by a background
+// writer, so the scheduling goroutine does not wait for the disk.
+type Recorder struct {
+	dir        string
+	maxRecords int
+
+	records chan *SessionRecord
+	pending sync.WaitGroup
+
+	// saved are the names of records in dir, the oldest first; it's only
+	// accessed by the writer after the recorder is created.
+	saved []string
+}
+
+// NewRecorder creates a Recorder which records the sessions into dir.
+func NewRecorder(dir string, maxRecords int) (*Recorder, error) {
+	if maxRecords <= 0 {
+		return nil, fmt.Errorf("the max number of session records must be positive, got %d", maxRecords)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	// The directory is only listed once; the records saved later are tracked
+	// in memory for rotation.
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Recorder{
+		dir:        dir,
+		maxRecords: maxRecords,
+		records:    make(chan *SessionRecord, recordQueueSize),
+	}
+	for _, info := range infos {
+		name := info.Name()
+		if strings.HasPrefix(name, recordFilePrefix) && strings.HasSuffix(name, recordFileSuffix) {
+			r.saved = append(r.saved, name)
+		}
+	}
+	sort.Strings(r.saved)
+
+	go r.run()
+
+	return r, nil
+}
+
+// run saves the records in background.
+func (r *Recorder) run() {
+	for record := range r.records {
+		if err := r.save(record); err != nil {
+			glog.Errorf("Failed to save the record of session: %v", err)
+		}
+		r.pending.Done()
+	}
+}
+
+// enqueue adds the record to be saved by the writer, or drops it if there are
+// too many records waiting.
+func (r *Recorder) enqueue(record *SessionRecord) {
+	r.pending.Add(1)
+	select {
+	case r.records <- record:
+	default:
+		r.pending.Done()
+		glog.Warningf("Too many session records waiting to be saved, drop the record of %v.", record.Timestamp)
+	}
+}
+
+// Flush waits until the records enqueued are saved.
+func (r *Recorder) Flush() {
+	r.pending.Wait()
+}
+
+// RecordSession returns a cache which records the snapshot and decisions of the session
+// opened on it, together with the scheduler configuration; the random source of node
+// choices is re-seeded for the session, and the index of node to start searching from is
+// recorded, so it can be replayed. The record is saved by RecordingCache.Save after the
+// session is closed.
+func (r *Recorder) RecordSession(c cache.Cache, actions []framework.Action,
+	tiers []conf.Tier, configurations []conf.Configuration, nodeSearch conf.NodeSearchConfiguration) *RecordingCache {
+	record := &SessionRecord{
+		Timestamp:  time.Now(),
+		Seed:       time.Now().UnixNano(),
+		NodeIndex:  util.GetLastProcessedNodeIndex(),
+		Tiers:      tiers,
+		NodeSearch: nodeSearch,
+
+		Configurations: configurations,
+	}
+	for _, action := range actions {
+		record.Actions = append(record.Actions, action.Name())
+	}
+	util.SetNodeRandSeed(record.Seed)
+
+	return &RecordingCache{
+		Cache:    c,
+		recorder: r,
+		record:   record,
+	}
+}
+
+func (r *Recorder) save(record *SessionRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("%s%020d%s", recordFilePrefix, record.Timestamp.UnixNano(), recordFileSuffix)
+	tmp := filepath.Join(r.dir, "."+name)
+	if err := ioutil.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, filepath.Join(r.dir, name)); err != nil {
+		return err
+	}
+	r.saved = append(r.saved, name)
+
+	return r.rotate()
+}
+
+// rotate removes the oldest records if there are more than maxRecords.
+func (r *Recorder) rotate() error {
+	for len(r.saved) > r.maxRecords {
+		if err := os.Remove(filepath.Join(r.dir, r.saved[0])); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		r.saved = r.saved[1:]
+	}
+
+	return nil
+}
+
+// RecordingCache is a cache.Cache which records the snapshot and decisions of session.
+type RecordingCache struct {
+	cache.Cache
+
+	recorder *Recorder
+
+	lock   sync.Mutex
+	record *SessionRecord
+}
+
+// Snapshot records the snapshot of cache.
+func (rc *RecordingCache) Snapshot() *api.ClusterInfo {
+	snapshot := rc.Cache.Snapshot()
+
+	rc.lock.Lock()
+	defer rc.lock.Unlock()
+	rc.record.setSnapshot(snapshot)
+
+	return snapshot
+}
+
+// Bind records the binding of task.
+func (rc *RecordingCache) Bind(task *api.TaskInfo, hostname string) error {
+	rc.lock.Lock()
+	rc.record.Decisions.Bindings = append(rc.record.Decisions.Bindings, Binding{
+		Task: taskKey(task),
+		Node: hostname,
+	})
+	rc.lock.Unlock()
+
+	return rc.Cache.Bind(task, hostname)
+}
+
+// Evict records the eviction of task.
+func (rc *RecordingCache) Evict(task *api.TaskInfo, reason string) error {
+	rc.lock.Lock()
+	rc.record.Decisions.Evictions = append(rc.record.Decisions.Evictions, Eviction{
+		Task:   taskKey(task),
+		Reason: reason,
+	})
+	rc.lock.Unlock()
+
+	return rc.Cache.Evict(task, reason)
+}
+
+// Save saves the record of session into the directory of recorder in background.
+func (rc *RecordingCache) Save() {
+	rc.lock.Lock()
+	defer rc.lock.Unlock()
+
+	rc.recorder.enqueue(rc.record)
+}
@@ -0,0 +1,129 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package replay
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/api"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/framework"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/util"
+)
+
+// Replay feeds the recorded snapshot through the recorded actions and plugins,
+// and returns the decisions of the session, which are the same as the recorded
+// ones, as the random node choices and the index of node to start searching from
+// are restored from the record.
+func Replay(record *SessionRecord) (*Decisions, error) {
+	var actions []framework.Action
+	for _, name := range record.Actions {
+		action, found := framework.GetAction(name)
+		if !found {
+			return nil, fmt.Errorf("failed to find Action %s", name)
+		}
+		action.Initialize(framework.GetArgOfActionFromConf(record.Configurations, name))
+		actions = append(actions, action)
+	}
+
+	util.SetNodeSearchConfiguration(record.NodeSearch)
+	util.SetLastProcessedNodeIndex(record.NodeIndex)
+	util.SetNodeRandSeed(record.Seed)
+
+	rc := &replayCache{snapshot: record.ClusterInfo()}
+	ssn := framework.OpenSession(rc, record.Tiers)
+	for _, action := range actions {
+		action.Execute(ssn)
+	}
+	framework.CloseSession(ssn)
+
+	return &rc.decisions, nil
+}
+
+// Equal returns whether the decisions are the same.
+func (d *Decisions) Equal(other *Decisions) bool {
+	return reflect.DeepEqual(d.Bindings, other.Bindings) &&
+		reflect.DeepEqual(d.Evictions, other.Evictions)
+}
+
+// replayCache is a cache.Cache of the recorded snapshot, which only records the
+// decisions of session.
+type replayCache struct {
+	snapshot *api.ClusterInfo
+
+	lock      sync.Mutex
+	decisions Decisions
+}
+
+func (rc *replayCache) Run(stopCh <-chan struct{}) {}
+
+func (rc *replayCache) Snapshot() *api.ClusterInfo {
+	return rc.snapshot
+}
+
+func (rc *replayCache) WaitForCacheSync(stopCh <-chan struct{}) bool {
+	return true
+}
+
+func (rc *replayCache) Bind(task *api.TaskInfo, hostname string) error {
+	rc.lock.Lock()
+	defer rc.lock.Unlock()
+
+	rc.decisions.Bindings = append(rc.decisions.Bindings, Binding{
+		Task: taskKey(task),
+		Node: hostname,
+	})
+	return nil
+}
+
+func (rc *replayCache) Evict(task *api.TaskInfo, reason string) error {
+	rc.lock.Lock()
+	defer rc.lock.Unlock()
+
+	rc.decisions.Evictions = append(rc.decisions.Evictions, Eviction{
+		Task:   taskKey(task),
+		Reason: reason,
+	})
+	return nil
+}
+
+func (rc *replayCache) EvictionRejected(task *api.TaskInfo) bool {
+	return false
+}
+
+func (rc *replayCache) RecordJobStatusEvent(job *api.JobInfo) {}
+
+func (rc *replayCache) UpdateJobStatus(job *api.JobInfo, updatePG bool) (*api.JobInfo, error) {
+	return job, nil
+}
+
+func (rc *replayCache) UpdateQueueStatus(queue *api.QueueInfo) error {
+	return nil
+}
+
+func (rc *replayCache) AllocateVolumes(task *api.TaskInfo, hostname string) error {
+	return nil
+}
+
+func (rc *replayCache) BindVolumes(task *api.TaskInfo) error {
+	return nil
+}
+
+func (rc *replayCache) RevertVolumes(task *api.TaskInfo) error {
+	return nil
+}
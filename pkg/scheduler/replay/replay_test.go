@@ -0,0 +1,160 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package replay
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	kbv1 "github.com/kubernetes-sigs/kube-batch/pkg/apis/scheduling/v1alpha1"
+	_ "github.com/kubernetes-sigs/kube-batch/pkg/scheduler/actions"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/cache"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/conf"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/framework"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/plugins"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/util"
+)
+
+func buildCache() *cache.SchedulerCache {
+	sc := cache.NewOfflineCache("kube-batch", "default")
+	sc.Binder = &util.FakeBinder{
+		Binds:   map[string]string{},
+		Channel: make(chan string, 100),
+	}
+	sc.Evictor = &util.FakeEvictor{
+		Evicts:  make([]string, 0),
+		Channel: make(chan string, 100),
+	}
+	sc.StatusUpdater = &util.FakeStatusUpdater{}
+	sc.VolumeBinder = &util.FakeVolumeBinder{}
+	sc.Recorder = &record.FakeRecorder{}
+
+	for i := 1; i <= 3; i++ {
+		alloc := util.BuildResourceList("4", "8Gi")
+		alloc[v1.ResourcePods] = resource.MustParse("10")
+		sc.AddNode(util.BuildNode(fmt.Sprintf("n%d", i), alloc, make(map[string]string)))
+	}
+	sc.AddQueue(&kbv1.Queue{
+		ObjectMeta: metav1.ObjectMeta{Name: "default"},
+		Spec:       kbv1.QueueSpec{Weight: 1},
+	})
+	sc.AddPodGroup(&kbv1.PodGroup{
+		ObjectMeta: metav1.ObjectMeta{Name: "pg1", Namespace: "c1"},
+		Spec:       kbv1.PodGroupSpec{MinMember: 2, Queue: "default"},
+		Status:     kbv1.PodGroupStatus{Phase: kbv1.PodGroupInqueue},
+	})
+	for i := 1; i <= 4; i++ {
+		sc.AddPod(util.BuildPod("c1", fmt.Sprintf("p%d", i), "", v1.PodPending,
+			util.BuildResourceList("1", "1Gi"), "pg1", make(map[string]string), make(map[string]string)))
+	}
+	sc.AddPod(util.BuildPod("c1", "running", "n2", v1.PodRunning,
+		util.BuildResourceList("1", "1Gi"), "pg1", make(map[string]string), make(map[string]string)))
+
+	return sc
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	dir, err := ioutil.TempDir("", "session-records")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	recorder, err := NewRecorder(dir, 2)
+	if err != nil {
+		t.Fatalf("Failed to create recorder: %v", err)
+	}
+
+	action, _ := framework.GetAction("allocate")
+	tiers := []conf.Tier{
+		{
+			Plugins: []conf.PluginOption{
+				{Name: "priority"},
+				{Name: "gang"},
+			},
+		},
+		{
+			Plugins: []conf.PluginOption{
+				{Name: "drf"},
+				{Name: "predicates"},
+				{Name: "proportion"},
+				{Name: "nodeorder"},
+			},
+		},
+	}
+	for i := range tiers {
+		for j := range tiers[i].Plugins {
+			plugins.ApplyPluginConfDefaults(&tiers[i].Plugins[j])
+		}
+	}
+
+	// Only part of nodes are searched for each task, which is replayed exactly
+	// by the recorded index of node to start searching from.
+	minNodes := int32(0)
+	nodeSearch := conf.NodeSearchConfiguration{
+		PercentageOfNodesToFind: 50,
+		MinNodesToFind:          &minNodes,
+	}
+	util.ApplyNodeSearchDefaults(&nodeSearch)
+	util.SetNodeSearchConfiguration(nodeSearch)
+	defer util.SetNodeSearchConfiguration(conf.NodeSearchConfiguration{})
+	for i := 0; i < 3; i++ {
+		rc := recorder.RecordSession(buildCache(), []framework.Action{action}, tiers, nil, nodeSearch)
+		ssn := framework.OpenSession(rc, tiers)
+		action.Execute(ssn)
+		framework.CloseSession(ssn)
+		rc.Save()
+	}
+	recorder.Flush()
+
+	files, err := filepath.Glob(filepath.Join(dir, recordFilePrefix+"*"+recordFileSuffix))
+	if err != nil {
+		t.Fatalf("Failed to list records: %v", err)
+	}
+	if len(files) != 2 {
+		t.Fatalf("expected 2 records after rotation, got %d", len(files))
+	}
+
+	for _, file := range files {
+		record, err := LoadSessionRecord(file)
+		if err != nil {
+			t.Fatalf("Failed to load record: %v", err)
+		}
+		if len(record.Decisions.Bindings) != 4 {
+			t.Errorf("expected 4 bindings recorded, got %v", record.Decisions.Bindings)
+		}
+
+		// Replay several times, the decisions must be the same every time.
+		for i := 0; i < 5; i++ {
+			decisions, err := Replay(record)
+			if err != nil {
+				t.Fatalf("Failed to replay record: %v", err)
+			}
+			if !decisions.Equal(&record.Decisions) {
+				t.Errorf("expected replayed decisions %+v, got %+v", record.Decisions, decisions)
+			}
+		}
+	}
+}
@@ -17,17 +17,23 @@ limitations under the License.
 package scheduler
 
 import (
+	"fmt"
+	"os"
 	"time"
 
 	"github.com/golang/glog"
 
+	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
 
 	schedcache "github.com/kubernetes-sigs/kube-batch/pkg/scheduler/cache"
 	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/conf"
 	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/framework"
 	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/metrics"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/replay"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/util"
 )
 
 // Scheduler watches for new unscheduled pods for kubebatch. It attempts to find
@@ -37,8 +43,20 @@ type Scheduler struct {
 	config         *rest.Config
 	actions        []framework.Action
 	plugins        []conf.Tier
+	configurations []conf.Configuration
+	nodeSearch     conf.NodeSearchConfiguration
 	schedulerConf  string
 	schedulePeriod time.Duration
+
+	// lastConfData and lastConfModTime are the content and modification time of
+	// scheduler configuration file read last time; the file is only read again
+	// when it's modified, and reloaded when the content is changed.
+	lastConfData    string
+	lastConfModTime time.Time
+	recorder        record.EventRecorder
+
+	// sessionRecorder records the scheduling sessions for replay if set.
+	sessionRecorder *replay.Recorder
 }
 
 // NewScheduler returns a scheduler
@@ -49,38 +67,47 @@ func NewScheduler(
 	period time.Duration,
 	defaultQueue string,
 ) (*Scheduler, error) {
+	cache := schedcache.New(config, schedulerName, defaultQueue)
+
 	scheduler := &Scheduler{
 		config:         config,
 		schedulerConf:  conf,
-		cache:          schedcache.New(config, schedulerName, defaultQueue),
+		cache:          cache,
 		schedulePeriod: period,
 	}
-
-	return scheduler, nil
-}
-
-// Run runs the Scheduler
-func (pc *Scheduler) Run(stopCh <-chan struct{}) {
-	var err error
-
-	// Start cache for policy.
-	go pc.cache.Run(stopCh)
-	pc.cache.WaitForCacheSync(stopCh)
+	// Reuse the event recorder of cache for the events of scheduler.
+	if sc, ok := cache.(*schedcache.SchedulerCache); ok {
+		scheduler.recorder = sc.Recorder
+	}
 
 	// Load configuration of scheduler
 	schedConf := defaultSchedulerConf
-	if len(pc.schedulerConf) != 0 {
-		if schedConf, err = readSchedulerConf(pc.schedulerConf); err != nil {
+	if len(scheduler.schedulerConf) != 0 {
+		confData, modTime, err := readSchedulerConfWithModTime(scheduler.schedulerConf)
+		if err != nil {
 			glog.Errorf("Failed to read scheduler configuration '%s', using default configuration: %v",
-				pc.schedulerConf, err)
-			schedConf = defaultSchedulerConf
+				scheduler.schedulerConf, err)
+		} else {
+			schedConf = confData
+			scheduler.lastConfData = confData
+			scheduler.lastConfModTime = modTime
 		}
 	}
 
-	pc.actions, pc.plugins, err = loadSchedulerConf(schedConf)
+	actions, schedulerConf, err := LoadSchedulerConf(schedConf)
 	if err != nil {
-		panic(err)
+		return nil, fmt.Errorf("failed to load scheduler configuration '%s': %v", scheduler.schedulerConf, err)
 	}
+	scheduler.applySchedulerConf(actions, schedulerConf)
+
+	return scheduler, nil
+}
+
+// Run runs the Scheduler
+func (pc *Scheduler) Run(stopCh <-chan struct{}) {
+	// Start cache for policy.
+	go pc.cache.Run(stopCh)
+	pc.cache.WaitForCacheSync(stopCh)
 
 	go wait.Until(pc.runOnce, pc.schedulePeriod, stopCh)
 }
@@ -91,7 +118,18 @@ func (pc *Scheduler) runOnce() {
 	defer glog.V(4).Infof("End scheduling ...")
 	defer metrics.UpdateE2eDuration(metrics.Duration(scheduleStartTime))
 
-	ssn := framework.OpenSession(pc.cache, pc.plugins)
+	// Reload the configuration between scheduling cycles, so the actions and
+	// plugins are not changed in a session.
+	pc.reloadSchedulerConf()
+
+	var cache schedcache.Cache = pc.cache
+	if pc.sessionRecorder != nil {
+		rc := pc.sessionRecorder.RecordSession(pc.cache, pc.actions, pc.plugins, pc.configurations, pc.nodeSearch)
+		defer rc.Save()
+		cache = rc
+	}
+
+	ssn := framework.OpenSession(cache, pc.plugins)
 	defer framework.CloseSession(ssn)
 
 	for _, action := range pc.actions {
@@ -100,3 +138,91 @@ func (pc *Scheduler) runOnce() {
 		metrics.UpdateActionDuration(action.Name(), metrics.Duration(actionStartTime))
 	}
 }
+
+// SetSessionRecorder sets the recorder of scheduling sessions.
+func (pc *Scheduler) SetSessionRecorder(recorder *replay.Recorder) {
+	pc.sessionRecorder = recorder
+}
+
+// Cache returns the cache of scheduler.
+func (pc *Scheduler) Cache() schedcache.Cache {
+	return pc.cache
+}
+
+// applySchedulerConf replaces the actions, plugins and their configurations of scheduler;
+// the actions are initialized with their arguments in the configurations.
+func (pc *Scheduler) applySchedulerConf(actions []framework.Action, schedulerConf *conf.SchedulerConfiguration) {
+	for _, action := range pc.actions {
+		action.UnInitialize()
+	}
+	for _, action := range actions {
+		action.Initialize(framework.GetArgOfActionFromConf(schedulerConf.Configurations, action.Name()))
+	}
+
+	pc.actions = actions
+	pc.plugins = schedulerConf.Tiers
+	pc.configurations = schedulerConf.Configurations
+	pc.nodeSearch = schedulerConf.NodeSearch
+	util.SetNodeSearchConfiguration(pc.nodeSearch)
+}
+
+// reloadSchedulerConf reloads the scheduler configuration file if it's modified;
+// if the new configuration is invalid, the last good one is kept.
+func (pc *Scheduler) reloadSchedulerConf() {
+	if len(pc.schedulerConf) == 0 {
+		return
+	}
+
+	info, err := os.Stat(pc.schedulerConf)
+	if err != nil {
+		glog.Errorf("Failed to stat scheduler configuration '%s', keep the current configuration: %v",
+			pc.schedulerConf, err)
+		return
+	}
+	if info.ModTime().Equal(pc.lastConfModTime) {
+		return
+	}
+
+	confData, err := readSchedulerConf(pc.schedulerConf)
+	if err != nil {
+		glog.Errorf("Failed to read scheduler configuration '%s', keep the current configuration: %v",
+			pc.schedulerConf, err)
+		return
+	}
+	pc.lastConfModTime = info.ModTime()
+
+	if confData == pc.lastConfData {
+		return
+	}
+	pc.lastConfData = confData
+
+	actions, schedulerConf, err := LoadSchedulerConf(confData)
+	if err != nil {
+		glog.Errorf("Failed to load scheduler configuration '%s', keep the current configuration: %v",
+			pc.schedulerConf, err)
+		pc.recordEvent(v1.EventTypeWarning, "InvalidSchedulerConf",
+			fmt.Sprintf("Failed to load scheduler configuration %s, keep the current configuration: %v", pc.schedulerConf, err))
+		return
+	}
+
+	pc.applySchedulerConf(actions, schedulerConf)
+
+	glog.V(3).Infof("Reloaded scheduler configuration '%s'.", pc.schedulerConf)
+	pc.recordEvent(v1.EventTypeNormal, "SchedulerConfReloaded",
+		fmt.Sprintf("Reloaded scheduler configuration %s", pc.schedulerConf))
+}
+
+// recordEvent records event on the pod of scheduler, which is given by env
+// POD_NAMESPACE and POD_NAME; no event is recorded if not given.
+func (pc *Scheduler) recordEvent(eventType, reason, message string) {
+	namespace, name := os.Getenv("POD_NAMESPACE"), os.Getenv("POD_NAME")
+	if pc.recorder == nil || len(namespace) == 0 || len(name) == 0 {
+		return
+	}
+
+	pc.recorder.Event(&v1.ObjectReference{
+		Kind:      "Pod",
+		Namespace: namespace,
+		Name:      name,
+	}, eventType, reason, message)
+}
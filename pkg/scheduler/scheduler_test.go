@@ -0,0 +1,227 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"k8s.io/client-go/tools/record"
+
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/framework"
+)
+
+func actionNames(actions []framework.Action) string {
+	var names []string
+	for _, action := range actions {
+		names = append(names, action.Name())
+	}
+	return strings.Join(names, ",")
+}
+
+// fakeAction records the arguments it's initialized with.
+type fakeAction struct {
+	arguments   framework.Arguments
+	initialized bool
+}
+
+func (fa *fakeAction) Name() string {
+	return "fake"
+}
+
+func (fa *fakeAction) Initialize(arguments framework.Arguments) {
+	fa.arguments = arguments
+	fa.initialized = true
+}
+
+func (fa *fakeAction) Execute(ssn *framework.Session) {}
+
+func (fa *fakeAction) UnInitialize() {
+	fa.arguments = nil
+	fa.initialized = false
+}
+
+func TestApplySchedulerConf(t *testing.T) {
+	action := &fakeAction{}
+	framework.RegisterAction(action)
+
+	pc := &Scheduler{}
+
+	actions, schedulerConf, err := LoadSchedulerConf(`
+actions: "fake"
+configurations:
+- name: fake
+  arguments:
+    fake.key: value
+tiers:
+- plugins:
+  - name: priority
+`)
+	if err != nil {
+		t.Fatalf("Failed to load scheduler configuration: %v", err)
+	}
+	pc.applySchedulerConf(actions, schedulerConf)
+
+	if !action.initialized || action.arguments["fake.key"] != "value" {
+		t.Errorf("expected action initialized with arguments %v, got %t with %v",
+			schedulerConf.Configurations[0].Arguments, action.initialized, action.arguments)
+	}
+
+	actions, schedulerConf, err = LoadSchedulerConf(`
+actions: "allocate"
+tiers:
+- plugins:
+  - name: priority
+`)
+	if err != nil {
+		t.Fatalf("Failed to load scheduler configuration: %v", err)
+	}
+	pc.applySchedulerConf(actions, schedulerConf)
+
+	if action.initialized {
+		t.Errorf("expected action un-initialized after it's removed, got arguments %v", action.arguments)
+	}
+}
+
+func TestReloadSchedulerConf(t *testing.T) {
+	dir, err := ioutil.TempDir("", "scheduler-conf")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	os.Setenv("POD_NAMESPACE", "kube-system")
+	os.Setenv("POD_NAME", "kube-batch")
+	defer os.Unsetenv("POD_NAMESPACE")
+	defer os.Unsetenv("POD_NAME")
+
+	confPath := filepath.Join(dir, "kube-batch.conf")
+	recorder := record.NewFakeRecorder(10)
+	pc := &Scheduler{
+		schedulerConf: confPath,
+		recorder:      recorder,
+	}
+
+	actions, schedulerConf, err := LoadSchedulerConf(defaultSchedulerConf)
+	if err != nil {
+		t.Fatalf("Failed to load default scheduler configuration: %v", err)
+	}
+	pc.applySchedulerConf(actions, schedulerConf)
+
+	tests := []struct {
+		name     string
+		conf     string
+		modTime  int
+		actions  string
+		plugins  int
+		event    string
+		expected bool
+	}{
+		{
+			name: "valid configuration is reloaded",
+			conf: `
+actions: "allocate"
+tiers:
+- plugins:
+  - name: priority
+  - name: gang
+  - name: conformance
+`,
+			modTime:  1,
+			actions:  "allocate",
+			plugins:  3,
+			event:    "Normal SchedulerConfReloaded",
+			expected: true,
+		},
+		{
+			name: "invalid configuration keeps the last good one",
+			conf: `
+actions: "allocate, unknown"
+tiers:
+- plugins:
+  - name: priority
+`,
+			modTime:  2,
+			actions:  "allocate",
+			plugins:  3,
+			event:    "Warning InvalidSchedulerConf",
+			expected: true,
+		},
+		{
+			name: "unchanged configuration is not reloaded",
+			conf: `
+actions: "allocate, unknown"
+tiers:
+- plugins:
+  - name: priority
+`,
+			modTime:  3,
+			actions:  "allocate",
+			plugins:  3,
+			expected: false,
+		},
+		{
+			name: "configuration is not read if not modified",
+			conf: `
+actions: "allocate, backfill"
+tiers:
+- plugins:
+  - name: priority
+`,
+			modTime:  3,
+			actions:  "allocate",
+			plugins:  3,
+			expected: false,
+		},
+	}
+
+	baseTime := time.Now()
+
+	for i, test := range tests {
+		if err := ioutil.WriteFile(confPath, []byte(test.conf), 0644); err != nil {
+			t.Fatalf("case %d (%s): failed to write configuration: %v", i, test.name, err)
+		}
+		modTime := baseTime.Add(time.Duration(test.modTime) * time.Second)
+		if err := os.Chtimes(confPath, modTime, modTime); err != nil {
+			t.Fatalf("case %d (%s): failed to set modification time: %v", i, test.name, err)
+		}
+
+		pc.reloadSchedulerConf()
+
+		if got := actionNames(pc.actions); got != test.actions {
+			t.Errorf("case %d (%s): expected actions %s, got %s", i, test.name, test.actions, got)
+		}
+		if got := len(pc.plugins[0].Plugins); got != test.plugins {
+			t.Errorf("case %d (%s): expected %d plugins, got %d", i, test.name, test.plugins, got)
+		}
+
+		select {
+		case event := <-recorder.Events:
+			if !test.expected || !strings.HasPrefix(event, test.event) {
+				t.Errorf("case %d (%s): expected event %s, got %s", i, test.name, test.event, event)
+			}
+		default:
+			if test.expected {
+				t.Errorf("case %d (%s): expected event %s, got nothing", i, test.name, test.event)
+			}
+		}
+	}
+}
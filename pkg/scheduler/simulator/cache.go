@@ -0,0 +1,135 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package simulator
+
+import (
+	"fmt"
+	"sync"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+
+	kbv1 "github.com/kubernetes-sigs/kube-batch/pkg/apis/scheduling/v1alpha1"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/api"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/cache"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/util"
+)
+
+// simulatedCache is an in-memory cache.Cache: bound pods are running on the node
+// and evicted pods are deleted immediately, and the status of PodGroups and Queues
+// are updated into cache directly, so the next cycle observes them.
+type simulatedCache struct {
+	*cache.SchedulerCache
+
+	lock       sync.Mutex
+	placements []Placement
+	evictions  []Eviction
+}
+
+func newSimulatedCache(schedulerName, defaultQueue string) *simulatedCache {
+	sc := &simulatedCache{
+		SchedulerCache: cache.NewOfflineCache(schedulerName, defaultQueue),
+	}
+
+	sc.SchedulerCache.StatusUpdater = &statusUpdater{cache: sc.SchedulerCache}
+	sc.SchedulerCache.VolumeBinder = &util.FakeVolumeBinder{}
+	// Events are dropped by FakeRecorder without channel.
+	sc.SchedulerCache.Recorder = &record.FakeRecorder{}
+
+	return sc
+}
+
+// Bind runs the pod of task on the host.
+func (sc *simulatedCache) Bind(task *api.TaskInfo, hostname string) error {
+	sc.SchedulerCache.Lock()
+	_, found := sc.SchedulerCache.Nodes[hostname]
+	sc.SchedulerCache.Unlock()
+	if !found {
+		return fmt.Errorf("failed to bind Task %v to host %v, host does not exist",
+			task.UID, hostname)
+	}
+
+	pod := task.Pod.DeepCopy()
+	pod.Spec.NodeName = hostname
+	pod.Status.Phase = v1.PodRunning
+	sc.SchedulerCache.UpdatePod(task.Pod, pod)
+
+	sc.lock.Lock()
+	defer sc.lock.Unlock()
+	sc.placements = append(sc.placements, Placement{
+		Pod:  podKey(pod),
+		Node: hostname,
+	})
+
+	return nil
+}
+
+// Evict deletes the pod of task.
+func (sc *simulatedCache) Evict(task *api.TaskInfo, reason string) error {
+	sc.SchedulerCache.DeletePod(task.Pod)
+
+	sc.lock.Lock()
+	defer sc.lock.Unlock()
+	sc.evictions = append(sc.evictions, Eviction{
+		Pod:    podKey(task.Pod),
+		Node:   task.NodeName,
+		Reason: reason,
+	})
+
+	return nil
+}
+
+// flush returns the placements and evictions since last flush.
+func (sc *simulatedCache) flush() ([]Placement, []Eviction) {
+	sc.lock.Lock()
+	defer sc.lock.Unlock()
+
+	placements, evictions := sc.placements, sc.evictions
+	sc.placements, sc.evictions = nil, nil
+
+	return placements, evictions
+}
+
+func podKey(pod *v1.Pod) string {
+	return fmt.Sprintf("%s/%s", pod.Namespace, pod.Name)
+}
+
+// statusUpdater updates the status of PodGroups and Queues into cache.
+type statusUpdater struct {
+	cache *cache.SchedulerCache
+}
+
+// UpdatePodCondition does nothing, the condition of pod is not used by scheduling.
+func (su *statusUpdater) UpdatePodCondition(pod *v1.Pod, condition *v1.PodCondition) (*v1.Pod, error) {
+	return pod, nil
+}
+
+// UpdatePodGroup updates the PodGroup in cache.
+func (su *statusUpdater) UpdatePodGroup(pg *kbv1.PodGroup) (*kbv1.PodGroup, error) {
+	newPG := pg.DeepCopy()
+	su.cache.UpdatePodGroup(pg, newPG)
+
+	return newPG, nil
+}
+
+// UpdateQueueStatus updates the Queue in cache.
+func (su *statusUpdater) UpdateQueueStatus(queue *kbv1.Queue) (*kbv1.Queue, error) {
+	newQueue := queue.DeepCopy()
+	su.cache.UpdateQueue(queue, newQueue)
+
+	return newQueue, nil
+}
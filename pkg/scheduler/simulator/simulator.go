@@ -0,0 +1,159 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package simulator
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/golang/glog"
+
+	kbv1 "github.com/kubernetes-sigs/kube-batch/pkg/apis/scheduling/v1alpha1"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/conf"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/framework"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/util"
+)
+
+// Placement is a pod bound to node in a cycle.
+type Placement struct {
+	Pod  string `yaml:"pod"`
+	Node string `yaml:"node"`
+}
+
+// Eviction is a pod evicted from node in a cycle.
+type Eviction struct {
+	Pod    string `yaml:"pod"`
+	Node   string `yaml:"node"`
+	Reason string `yaml:"reason"`
+}
+
+// PodGroupStatus is the phase of PodGroup at the end of a cycle.
+type PodGroupStatus struct {
+	PodGroup string             `yaml:"podGroup"`
+	Queue    string             `yaml:"queue"`
+	Phase    kbv1.PodGroupPhase `yaml:"phase"`
+}
+
+// CycleResult is the result of a virtual scheduling cycle.
+type CycleResult struct {
+	Cycle      int              `yaml:"cycle"`
+	Placements []Placement      `yaml:"placements,omitempty"`
+	Evictions  []Eviction       `yaml:"evictions,omitempty"`
+	PodGroups  []PodGroupStatus `yaml:"podGroups,omitempty"`
+}
+
+// simulationSeed seeds the selection among the nodes with same score, so the
+// simulation of the same trace and configuration gives the same result.
+const simulationSeed int64 = 1
+
+// Simulator runs the actions and plugins of scheduler configuration against
+// a cluster trace in memory, without connecting to the API server.
+type Simulator struct {
+	cache   *simulatedCache
+	actions []framework.Action
+	plugins []conf.Tier
+}
+
+// New returns a Simulator of the scheduler configuration, whose cache is
+// loaded with the objects of trace.
+func New(schedulerConf string, schedulerName string, defaultQueue string, trace *Trace) (*Simulator, error) {
+	actions, schedulerConfiguration, err := scheduler.LoadSchedulerConf(schedulerConf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load scheduler configuration: %v", err)
+	}
+	for _, action := range actions {
+		action.Initialize(framework.GetArgOfActionFromConf(schedulerConfiguration.Configurations, action.Name()))
+	}
+	util.SetNodeSearchConfiguration(schedulerConfiguration.NodeSearch)
+	util.SetNodeRandSeed(simulationSeed)
+	util.SetLastProcessedNodeIndex(0)
+
+	sc := newSimulatedCache(schedulerName, defaultQueue)
+	for _, pc := range trace.PriorityClasses {
+		sc.AddPriorityClass(pc)
+	}
+	for _, queue := range trace.Queues {
+		sc.AddQueue(queue)
+	}
+	for _, node := range trace.Nodes {
+		sc.AddNode(node)
+	}
+	for _, pg := range trace.PodGroups {
+		sc.AddPodGroup(pg)
+	}
+	for _, pod := range trace.Pods {
+		sc.AddPod(pod)
+	}
+
+	return &Simulator{
+		cache:   sc,
+		actions: actions,
+		plugins: schedulerConfiguration.Tiers,
+	}, nil
+}
+
+// Run runs the given number of virtual scheduling cycles.
+func (s *Simulator) Run(cycles int) []*CycleResult {
+	var results []*CycleResult
+	for i := 1; i <= cycles; i++ {
+		results = append(results, s.runOnce(i))
+	}
+	return results
+}
+
+func (s *Simulator) runOnce(cycle int) *CycleResult {
+	glog.V(3).Infof("Start simulating cycle <%d> ...", cycle)
+	defer glog.V(3).Infof("End simulating cycle <%d> ...", cycle)
+
+	ssn := framework.OpenSession(s.cache, s.plugins)
+	for _, action := range s.actions {
+		action.Execute(ssn)
+	}
+	framework.CloseSession(ssn)
+
+	result := &CycleResult{
+		Cycle:     cycle,
+		PodGroups: s.podGroups(),
+	}
+	result.Placements, result.Evictions = s.cache.flush()
+
+	return result
+}
+
+// podGroups returns the status of PodGroups in cache, sorted by name.
+func (s *Simulator) podGroups() []PodGroupStatus {
+	s.cache.SchedulerCache.Lock()
+	defer s.cache.SchedulerCache.Unlock()
+
+	var podGroups []PodGroupStatus
+	for _, job := range s.cache.SchedulerCache.Jobs {
+		if job.PodGroup == nil {
+			continue
+		}
+		podGroups = append(podGroups, PodGroupStatus{
+			PodGroup: fmt.Sprintf("%s/%s", job.PodGroup.Namespace, job.PodGroup.Name),
+			Queue:    string(job.Queue),
+			Phase:    job.PodGroup.Status.Phase,
+		})
+	}
+	sort.Slice(podGroups, func(i, j int) bool {
+		return podGroups[i].PodGroup < podGroups[j].PodGroup
+	})
+
+	return podGroups
+}
@@ -0,0 +1,219 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package simulator
+
+import (
+	"reflect"
+	"testing"
+
+	kbv1 "github.com/kubernetes-sigs/kube-batch/pkg/apis/scheduling/v1alpha1"
+	_ "github.com/kubernetes-sigs/kube-batch/pkg/scheduler/actions"
+)
+
+const simulatorConf = `
+actions: "enqueue, allocate, backfill"
+tiers:
+- plugins:
+  - name: priority
+  - name: gang
+- plugins:
+  - name: drf
+  - name: predicates
+  - name: proportion
+  - name: nodeorder
+`
+
+const simulatorTrace = `
+apiVersion: v1
+kind: Node
+metadata:
+  name: n1
+status:
+  allocatable:
+    cpu: "2"
+    memory: 4Gi
+    pods: "110"
+  capacity:
+    cpu: "2"
+    memory: 4Gi
+    pods: "110"
+---
+apiVersion: scheduling.incubator.k8s.io/v1alpha1
+kind: Queue
+metadata:
+  name: default
+spec:
+  weight: 1
+---
+apiVersion: scheduling.incubator.k8s.io/v1alpha1
+kind: PodGroup
+metadata:
+  name: pg1
+  namespace: c1
+spec:
+  minMember: 2
+  queue: default
+---
+apiVersion: scheduling.incubator.k8s.io/v1alpha1
+kind: PodGroup
+metadata:
+  name: pg2
+  namespace: c1
+spec:
+  minMember: 1
+  queue: default
+---
+apiVersion: v1
+kind: List
+items:
+- apiVersion: v1
+  kind: Pod
+  metadata:
+    name: p1
+    namespace: c1
+    uid: c1-p1
+    annotations:
+      scheduling.k8s.io/group-name: pg1
+  spec:
+    schedulerName: kube-batch
+    containers:
+    - name: c
+      resources:
+        requests:
+          cpu: "1"
+          memory: 1Gi
+  status:
+    phase: Pending
+- apiVersion: v1
+  kind: Pod
+  metadata:
+    name: p2
+    namespace: c1
+    uid: c1-p2
+    annotations:
+      scheduling.k8s.io/group-name: pg1
+  spec:
+    schedulerName: kube-batch
+    containers:
+    - name: c
+      resources:
+        requests:
+          cpu: "1"
+          memory: 1Gi
+  status:
+    phase: Pending
+- apiVersion: v1
+  kind: Pod
+  metadata:
+    name: p3
+    namespace: c1
+    uid: c1-p3
+    annotations:
+      scheduling.k8s.io/group-name: pg2
+  spec:
+    schedulerName: kube-batch
+    containers:
+    - name: c
+      resources:
+        requests:
+          cpu: "3"
+          memory: 1Gi
+  status:
+    phase: Pending
+`
+
+func TestSimulator(t *testing.T) {
+	trace := &Trace{}
+	if err := trace.load([]byte(simulatorTrace)); err != nil {
+		t.Fatalf("Failed to load trace: %v", err)
+	}
+	if len(trace.Nodes) != 1 || len(trace.Queues) != 1 || len(trace.PodGroups) != 2 || len(trace.Pods) != 3 {
+		t.Fatalf("Unexpected objects in trace: %+v", trace)
+	}
+
+	sim, err := New(simulatorConf, "kube-batch", "default", trace)
+	if err != nil {
+		t.Fatalf("Failed to create simulator: %v", err)
+	}
+
+	results := sim.Run(2)
+	if len(results) != 2 {
+		t.Fatalf("expected 2 cycles, got %d", len(results))
+	}
+
+	placements := map[string]string{}
+	for _, p := range results[0].Placements {
+		placements[p.Pod] = p.Node
+	}
+	expectedPlacements := map[string]string{
+		"c1/p1": "n1",
+		"c1/p2": "n1",
+	}
+	if !reflect.DeepEqual(placements, expectedPlacements) {
+		t.Errorf("cycle 1: expected placements %v, got %v", expectedPlacements, placements)
+	}
+
+	// The placements are not repeated in the next cycle.
+	if len(results[1].Placements) != 0 || len(results[1].Evictions) != 0 {
+		t.Errorf("cycle 2: expected no placements and evictions, got %+v", results[1])
+	}
+
+	expectedPodGroups := []PodGroupStatus{
+		{PodGroup: "c1/pg1", Queue: "default", Phase: kbv1.PodGroupRunning},
+		{PodGroup: "c1/pg2", Queue: "default", Phase: kbv1.PodGroupInqueue},
+	}
+	if !reflect.DeepEqual(results[1].PodGroups, expectedPodGroups) {
+		t.Errorf("cycle 2: expected PodGroups %+v, got %+v", expectedPodGroups, results[1].PodGroups)
+	}
+
+	// The simulation of the same trace gives the same placements in every run.
+	expected := simulatePlacements(t)
+	for i := 0; i < 5; i++ {
+		if got := simulatePlacements(t); !reflect.DeepEqual(expected, got) {
+			t.Fatalf("run %d: expected placements %v, got %v", i, expected, got)
+		}
+	}
+}
+
+// simulatePlacements returns the placements of each cycle of a new simulation
+// on three identical nodes, so the nodes with same score are picked randomly.
+func simulatePlacements(t *testing.T) []map[string]string {
+	trace := &Trace{}
+	if err := trace.load([]byte(simulatorTrace)); err != nil {
+		t.Fatalf("Failed to load trace: %v", err)
+	}
+	for _, name := range []string{"n2", "n3"} {
+		node := trace.Nodes[0].DeepCopy()
+		node.Name = name
+		trace.Nodes = append(trace.Nodes, node)
+	}
+
+	sim, err := New(simulatorConf, "kube-batch", "default", trace)
+	if err != nil {
+		t.Fatalf("Failed to create simulator: %v", err)
+	}
+
+	var result []map[string]string
+	for _, cycle := range sim.Run(2) {
+		placements := map[string]string{}
+		for _, p := range cycle.Placements {
+			placements[p.Pod] = p.Node
+		}
+		result = append(result, placements)
+	}
+	return result
+}
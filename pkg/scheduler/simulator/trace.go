@@ -0,0 +1,178 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package simulator
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/api/scheduling/v1beta1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	k8sscheme "k8s.io/client-go/kubernetes/scheme"
+
+	kbv1 "github.com/kubernetes-sigs/kube-batch/pkg/apis/scheduling/v1alpha1"
+	kbscheme "github.com/kubernetes-sigs/kube-batch/pkg/client/clientset/versioned/scheme"
+)
+
+// traceDecoder decodes the objects of Kubernetes and kube-batch in trace.
+var traceDecoder runtime.Decoder
+
+func init() {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(k8sscheme.AddToScheme(scheme))
+	utilruntime.Must(kbscheme.AddToScheme(scheme))
+
+	traceDecoder = serializer.NewCodecFactory(scheme).UniversalDeserializer()
+}
+
+// Trace is the cluster trace to simulate, which includes the objects to
+// load into the cache of simulator.
+type Trace struct {
+	Nodes           []*v1.Node
+	Queues          []*kbv1.Queue
+	PriorityClasses []*v1beta1.PriorityClass
+	PodGroups       []*kbv1.PodGroup
+	Pods            []*v1.Pod
+}
+
+// LoadTrace loads the cluster trace from the YAML or JSON files; a directory
+// is expanded to its files with extension .yaml, .yml or .json. A file may
+// include several objects separated by "---".
+func LoadTrace(paths ...string) (*Trace, error) {
+	trace := &Trace{}
+
+	for _, path := range paths {
+		files, err := traceFiles(path)
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			if err := trace.loadFile(file); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return trace, nil
+}
+
+func traceFiles(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+
+	infos, err := ioutil.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, info := range infos {
+		if info.IsDir() {
+			continue
+		}
+		switch filepath.Ext(info.Name()) {
+		case ".yaml", ".yml", ".json":
+			files = append(files, filepath.Join(path, info.Name()))
+		}
+	}
+	sort.Strings(files)
+
+	return files, nil
+}
+
+func (t *Trace) loadFile(file string) error {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return err
+	}
+
+	if err := t.load(data); err != nil {
+		return fmt.Errorf("failed to load trace <%s>: %v", file, err)
+	}
+
+	return nil
+}
+
+func (t *Trace) load(data []byte) error {
+	reader := yaml.NewYAMLReader(bufio.NewReader(bytes.NewReader(data)))
+
+	for {
+		doc, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if len(strings.TrimSpace(string(doc))) == 0 {
+			continue
+		}
+
+		obj, _, err := traceDecoder.Decode(doc, nil, nil)
+		if err != nil {
+			return err
+		}
+		if err := t.add(obj); err != nil {
+			return err
+		}
+	}
+}
+
+func (t *Trace) add(obj runtime.Object) error {
+	switch o := obj.(type) {
+	case *v1.Node:
+		t.Nodes = append(t.Nodes, o)
+	case *kbv1.Queue:
+		t.Queues = append(t.Queues, o)
+	case *v1beta1.PriorityClass:
+		t.PriorityClasses = append(t.PriorityClasses, o)
+	case *kbv1.PodGroup:
+		t.PodGroups = append(t.PodGroups, o)
+	case *v1.Pod:
+		t.Pods = append(t.Pods, o)
+	case *v1.List:
+		for _, item := range o.Items {
+			obj, _, err := traceDecoder.Decode(item.Raw, nil, nil)
+			if err != nil {
+				return err
+			}
+			if err := t.add(obj); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("unsupported object %v in trace", obj.GetObjectKind().GroupVersionKind())
+	}
+
+	return nil
+}
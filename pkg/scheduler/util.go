@@ -19,13 +19,16 @@ package scheduler
 import (
 	"fmt"
 	"io/ioutil"
+	"os"
 	"strings"
+	"time"
 
 	yaml "gopkg.in/yaml.v2"
 
 	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/conf"
 	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/framework"
 	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/plugins"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/util"
 )
 
 var defaultSchedulerConf = `
@@ -41,7 +44,9 @@ tiers:
   - name: nodeorder
 `
 
-func loadSchedulerConf(confStr string) ([]framework.Action, []conf.Tier, error) {
+// LoadSchedulerConf loads and validates the scheduler configuration, and returns the
+// actions in order and the configuration with default settings.
+func LoadSchedulerConf(confStr string) ([]framework.Action, *conf.SchedulerConfiguration, error) {
 	var actions []framework.Action
 
 	schedulerConf := &conf.SchedulerConfiguration{}
@@ -49,7 +54,11 @@ func loadSchedulerConf(confStr string) ([]framework.Action, []conf.Tier, error)
 	buf := make([]byte, len(confStr))
 	copy(buf, confStr)
 
-	if err := yaml.Unmarshal(buf, schedulerConf); err != nil {
+	if err := yaml.UnmarshalStrict(buf, schedulerConf); err != nil {
+		return nil, nil, err
+	}
+
+	if err := validateSchedulerConf(schedulerConf); err != nil {
 		return nil, nil, err
 	}
 
@@ -60,6 +69,12 @@ func loadSchedulerConf(confStr string) ([]framework.Action, []conf.Tier, error)
 		}
 	}
 
+	// Set default settings for searching nodes if not set
+	util.ApplyNodeSearchDefaults(&schedulerConf.NodeSearch)
+	if err := util.ValidateNodeSearchConfiguration(&schedulerConf.NodeSearch); err != nil {
+		return nil, nil, err
+	}
+
 	actionNames := strings.Split(schedulerConf.Actions, ",")
 	for _, actionName := range actionNames {
 		if action, found := framework.GetAction(strings.TrimSpace(actionName)); found {
@@ -69,7 +84,7 @@ func loadSchedulerConf(confStr string) ([]framework.Action, []conf.Tier, error)
 		}
 	}
 
-	return actions, schedulerConf.Tiers, nil
+	return actions, schedulerConf, nil
 }
 
 func readSchedulerConf(confPath string) (string, error) {
@@ -79,3 +94,18 @@ func readSchedulerConf(confPath string) (string, error) {
 	}
 	return string(dat), nil
 }
+
+// readSchedulerConfWithModTime returns the content of configuration file and its
+// modification time before reading.
+func readSchedulerConfWithModTime(confPath string) (string, time.Time, error) {
+	info, err := os.Stat(confPath)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	confData, err := readSchedulerConf(confPath)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return confData, info.ModTime(), nil
+}
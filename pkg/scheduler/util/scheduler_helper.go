@@ -18,48 +18,188 @@ package util
 
 import (
 	"context"
+	"fmt"
 	"math"
 	"math/rand"
 	"sort"
 	"sync"
+	"time"
 
 	"github.com/golang/glog"
 	"k8s.io/client-go/util/workqueue"
 
 	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/api"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/conf"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/metrics"
 	schedulerapi "k8s.io/kubernetes/pkg/scheduler/api"
 )
 
-// PredicateNodes returns nodes that fit task
-func PredicateNodes(task *api.TaskInfo, nodes []*api.NodeInfo, fn api.PredicateFn) ([]*api.NodeInfo, *api.FitErrors) {
-	var predicateNodes []*api.NodeInfo
+const (
+	// DefaultPercentageOfNodesToFind is the default percentage of nodes to find, all nodes are searched by default
+	DefaultPercentageOfNodesToFind = 100
+	// DefaultMinNodesToFind is the default minimum number of feasible nodes to find
+	DefaultMinNodesToFind = 100
+	// DefaultNodeWorkers is the default number of workers to predicate and prioritize nodes
+	DefaultNodeWorkers = 16
+)
 
-	var workerLock sync.Mutex
+var (
+	nodeSearchConf = conf.NodeSearchConfiguration{
+		PercentageOfNodesToFind: DefaultPercentageOfNodesToFind,
+		MinNodesToFind:          int32Ptr(DefaultMinNodesToFind),
+		NodeWorkers:             DefaultNodeWorkers,
+	}
+	nodeSearchLock sync.RWMutex
 
-	var errorLock sync.Mutex
+	// lastProcessedNodeIndex is the index of node to start searching from in
+	// next PredicateNodes, so that the nodes are searched in round robin.
+	lastProcessedNodeIndex int
+	nodeIndexLock          sync.Mutex
+
+	// nodeRand picks the best node randomly among the nodes with same score, it's
+	// seeded by SetNodeRandSeed to replay the choices of a session.
+	nodeRand     = rand.New(rand.NewSource(time.Now().UnixNano()))
+	nodeRandLock sync.Mutex
+)
+
+// ApplyNodeSearchDefaults sets the fields of configuration to default value if not set
+func ApplyNodeSearchDefaults(c *conf.NodeSearchConfiguration) {
+	if c.PercentageOfNodesToFind == 0 {
+		c.PercentageOfNodesToFind = DefaultPercentageOfNodesToFind
+	}
+	if c.MinNodesToFind == nil {
+		c.MinNodesToFind = int32Ptr(DefaultMinNodesToFind)
+	}
+	if c.NodeWorkers == 0 {
+		c.NodeWorkers = DefaultNodeWorkers
+	}
+}
+
+// ValidateNodeSearchConfiguration checks whether the configuration is valid
+func ValidateNodeSearchConfiguration(c *conf.NodeSearchConfiguration) error {
+	if c.PercentageOfNodesToFind < 0 || c.PercentageOfNodesToFind > 100 {
+		return fmt.Errorf("percentageOfNodesToFind %d is not in range [0, 100], where 0 means the default %d",
+			c.PercentageOfNodesToFind, DefaultPercentageOfNodesToFind)
+	}
+	if c.MinNodesToFind != nil && *c.MinNodesToFind < 0 {
+		return fmt.Errorf("minNodesToFind %d is negative", *c.MinNodesToFind)
+	}
+	if c.NodeWorkers < 0 {
+		return fmt.Errorf("nodeWorkers %d is negative", c.NodeWorkers)
+	}
+	return nil
+}
+
+// SetNodeSearchConfiguration sets the configuration of searching nodes for tasks
+func SetNodeSearchConfiguration(c conf.NodeSearchConfiguration) {
+	ApplyNodeSearchDefaults(&c)
+	// Copy MinNodesToFind, so it's not changed by caller.
+	c.MinNodesToFind = int32Ptr(*c.MinNodesToFind)
+
+	nodeSearchLock.Lock()
+	nodeSearchConf = c
+	nodeSearchLock.Unlock()
+
+	metrics.UpdateNodeSearchConfiguration(c.PercentageOfNodesToFind, *c.MinNodesToFind, c.NodeWorkers)
+}
+
+// getNodeSearchConfiguration returns the configuration of searching nodes, whose
+// MinNodesToFind is always set.
+func getNodeSearchConfiguration() conf.NodeSearchConfiguration {
+	nodeSearchLock.RLock()
+	defer nodeSearchLock.RUnlock()
+
+	return nodeSearchConf
+}
+
+// CalculateNumOfFeasibleNodesToFind returns the number of feasible nodes that once found,
+// the scheduler stops searching more feasible nodes.
+func CalculateNumOfFeasibleNodesToFind(numAllNodes int32) int32 {
+	c := getNodeSearchConfiguration()
+	minNodes := *c.MinNodesToFind
+	if numAllNodes <= minNodes || c.PercentageOfNodesToFind >= 100 {
+		return numAllNodes
+	}
+
+	numNodes := numAllNodes * c.PercentageOfNodesToFind / 100
+	if numNodes < minNodes {
+		numNodes = minNodes
+	}
+	// At least one feasible node is searched for the task.
+	if numNodes < 1 {
+		numNodes = 1
+	}
+
+	return numNodes
+}
+
+// PredicateNodes returns nodes that fit task; it stops searching when enough
+// feasible nodes are found, and starts from where the last search stopped.
+// The nodes are checked by parallel workers in batches, and the feasible nodes
+// are taken in the order of search, so the result only depends on the start
+// index and the nodes, e.g. when a session is replayed.
+func PredicateNodes(task *api.TaskInfo, nodes []*api.NodeInfo, fn api.PredicateFn) ([]*api.NodeInfo, *api.FitErrors) {
 	fe := api.NewFitErrors()
 
-	checkNode := func(index int) {
-		node := nodes[index]
-		glog.V(3).Infof("Considering Task <%v/%v> on node <%v>: <%v> vs. <%v>",
-			task.Namespace, task.Name, node.Name, task.Resreq, node.Idle)
-
-		// TODO (k82cn): Enable eCache for performance improvement.
-		if err := fn(task, node); err != nil {
-			glog.V(3).Infof("Predicates failed for task <%s/%s> on node <%s>: %v",
-				task.Namespace, task.Name, node.Name, err)
-			errorLock.Lock()
-			fe.SetNodeError(node.Name, err)
-			errorLock.Unlock()
-			return
+	allNodes := len(nodes)
+	if allNodes == 0 {
+		return nil, fe
+	}
+	numNodesToFind := int(CalculateNumOfFeasibleNodesToFind(int32(allNodes)))
+	nodeWorkers := getNodeSearchConfiguration().NodeWorkers
+
+	// The search starts from where the last one stopped; the index is only
+	// updated at the end, so the concurrent searches may overlap but never
+	// race on the index.
+	startIndex := GetLastProcessedNodeIndex()
+
+	var predicateNodes []*api.NodeInfo
+	errs := make([]error, allNodes)
+	processedNodes, evaluatedNodes := 0, 0
+
+	for processedNodes < allNodes && len(predicateNodes) < numNodesToFind {
+		// Checks as many nodes as still needed in a batch, but at least one per worker.
+		batch := numNodesToFind - len(predicateNodes)
+		if batch < nodeWorkers {
+			batch = nodeWorkers
+		}
+		if batch > allNodes-processedNodes {
+			batch = allNodes - processedNodes
 		}
 
-		workerLock.Lock()
-		predicateNodes = append(predicateNodes, node)
-		workerLock.Unlock()
+		offset := processedNodes
+		workqueue.ParallelizeUntil(context.TODO(), nodeWorkers, batch, func(index int) {
+			node := nodes[(startIndex+offset+index)%allNodes]
+			glog.V(3).Infof("Considering Task <%v/%v> on node <%v>: <%v> vs. <%v>",
+				task.Namespace, task.Name, node.Name, task.Resreq, node.Idle)
+
+			errs[offset+index] = fn(task, node)
+		})
+		evaluatedNodes += batch
+
+		for index := offset; index < offset+batch; index++ {
+			processedNodes++
+
+			node := nodes[(startIndex+index)%allNodes]
+			if err := errs[index]; err != nil {
+				glog.V(3).Infof("Predicates failed for task <%s/%s> on node <%s>: %v",
+					task.Namespace, task.Name, node.Name, err)
+				fe.SetNodeError(node.Name, err)
+				continue
+			}
+
+			predicateNodes = append(predicateNodes, node)
+			if len(predicateNodes) == numNodesToFind {
+				break
+			}
+		}
 	}
 
-	workqueue.ParallelizeUntil(context.TODO(), 16, len(nodes), checkNode)
+	// The nodes checked after enough feasible ones are found are not counted, so
+	// they'll be searched firstly next time.
+	SetLastProcessedNodeIndex((startIndex + processedNodes) % allNodes)
+	metrics.UpdatePredicateEvaluatedNodes(evaluatedNodes)
+
 	return predicateNodes, fe
 }
 
@@ -91,7 +231,7 @@ func PrioritizeNodes(task *api.TaskInfo, nodes []*api.NodeInfo, batchFn api.Batc
 		nodeOrderScoreMap[node.Name] = orderScore
 		workerLock.Unlock()
 	}
-	workqueue.ParallelizeUntil(context.TODO(), 16, len(nodes), scoreNode)
+	workqueue.ParallelizeUntil(context.TODO(), getNodeSearchConfiguration().NodeWorkers, len(nodes), scoreNode)
 	reduceScores, err := reduceFn(task, pluginNodeScoreMap)
 	if err != nil {
 		glog.Errorf("Error in Calculating Priority for the node:%v", err)
@@ -137,7 +277,7 @@ func SortNodes(nodeScores map[float64][]*api.NodeInfo) []*api.NodeInfo {
 	}
 	sort.Sort(sort.Reverse(sort.Float64Slice(keys)))
 	for _, key := range keys {
-		nodes := nodeScores[key]
+		nodes := sortNodesByName(nodeScores[key])
 		nodesInorder = append(nodesInorder, nodes...)
 	}
 	return nodesInorder
@@ -154,14 +294,62 @@ func SelectBestNode(nodeScores map[float64][]*api.NodeInfo) *api.NodeInfo {
 		}
 	}
 
-	return bestNodes[rand.Intn(len(bestNodes))]
+	bestNodes = sortNodesByName(bestNodes)
+
+	nodeRandLock.Lock()
+	defer nodeRandLock.Unlock()
+	return bestNodes[nodeRand.Intn(len(bestNodes))]
+}
+
+// sortNodesByName returns a copy of nodes sorted by name, so that the nodes with
+// same score are picked in the same order regardless of the parallel scoring.
+func sortNodesByName(nodes []*api.NodeInfo) []*api.NodeInfo {
+	result := make([]*api.NodeInfo, len(nodes))
+	copy(result, nodes)
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+// SetNodeRandSeed seeds the random source which picks the best node among the
+// nodes with same score.
+func SetNodeRandSeed(seed int64) {
+	nodeRandLock.Lock()
+	defer nodeRandLock.Unlock()
+
+	nodeRand.Seed(seed)
+}
+
+// GetLastProcessedNodeIndex returns the index of node to start searching from in next PredicateNodes.
+func GetLastProcessedNodeIndex() int {
+	nodeIndexLock.Lock()
+	defer nodeIndexLock.Unlock()
+
+	return lastProcessedNodeIndex
+}
+
+// SetLastProcessedNodeIndex sets the index of node to start searching from in next PredicateNodes.
+func SetLastProcessedNodeIndex(index int) {
+	nodeIndexLock.Lock()
+	defer nodeIndexLock.Unlock()
+
+	lastProcessedNodeIndex = index
+}
+
+func int32Ptr(v int32) *int32 {
+	return &v
 }
 
-// GetNodeList returns values of the map 'nodes'
+// GetNodeList returns values of the map 'nodes' sorted by name, so that
+// PredicateNodes searches the nodes in round robin across calls.
 func GetNodeList(nodes map[string]*api.NodeInfo) []*api.NodeInfo {
 	result := make([]*api.NodeInfo, 0, len(nodes))
 	for _, v := range nodes {
 		result = append(result, v)
 	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
 	return result
 }
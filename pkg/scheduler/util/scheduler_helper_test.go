@@ -17,10 +17,12 @@ limitations under the License.
 package util
 
 import (
+	"fmt"
 	"reflect"
 	"testing"
 
 	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/api"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/conf"
 )
 
 func TestSelectBestNode(t *testing.T) {
@@ -61,3 +63,118 @@ func TestSelectBestNode(t *testing.T) {
 		}
 	}
 }
+
+func TestCalculateNumOfFeasibleNodesToFind(t *testing.T) {
+	defer SetNodeSearchConfiguration(conf.NodeSearchConfiguration{})
+
+	cases := []struct {
+		percentage int32
+		minNodes   int32
+		allNodes   int32
+		expected   int32
+	}{
+		{percentage: 100, minNodes: 100, allNodes: 5000, expected: 5000},
+		{percentage: 10, minNodes: 100, allNodes: 50, expected: 50},
+		{percentage: 10, minNodes: 100, allNodes: 5000, expected: 500},
+		{percentage: 1, minNodes: 100, allNodes: 5000, expected: 100},
+		{percentage: 1, minNodes: 0, allNodes: 5000, expected: 50},
+		{percentage: 1, minNodes: 0, allNodes: 50, expected: 1},
+	}
+
+	for i, test := range cases {
+		SetNodeSearchConfiguration(conf.NodeSearchConfiguration{
+			PercentageOfNodesToFind: test.percentage,
+			MinNodesToFind:          int32Ptr(test.minNodes),
+		})
+		if got := CalculateNumOfFeasibleNodesToFind(test.allNodes); got != test.expected {
+			t.Errorf("Failed test case #%d, expected: %d, got %d", i, test.expected, got)
+		}
+	}
+}
+
+func TestPredicateNodesRoundRobin(t *testing.T) {
+	SetNodeSearchConfiguration(conf.NodeSearchConfiguration{
+		PercentageOfNodesToFind: 10,
+		MinNodesToFind:          int32Ptr(2),
+		NodeWorkers:             1,
+	})
+	defer SetNodeSearchConfiguration(conf.NodeSearchConfiguration{})
+	SetLastProcessedNodeIndex(0)
+
+	nodes := map[string]*api.NodeInfo{}
+	for i := 0; i < 6; i++ {
+		name := fmt.Sprintf("node%d", i)
+		nodes[name] = &api.NodeInfo{Name: name}
+	}
+	task := &api.TaskInfo{Namespace: "c1", Name: "p1"}
+	fn := func(task *api.TaskInfo, node *api.NodeInfo) error {
+		if node.Name == "node1" {
+			return fmt.Errorf("node1 is not feasible")
+		}
+		return nil
+	}
+
+	expected := [][]string{
+		{"node0", "node2"},
+		{"node3", "node4"},
+		{"node5", "node0"},
+		{"node2", "node3"},
+	}
+	for i, names := range expected {
+		predicateNodes, _ := PredicateNodes(task, GetNodeList(nodes), fn)
+
+		var got []string
+		for _, node := range predicateNodes {
+			got = append(got, node.Name)
+		}
+		if !reflect.DeepEqual(got, names) {
+			t.Errorf("Failed round #%d, expected: %v, got %v", i, names, got)
+		}
+	}
+}
+
+func TestPredicateNodesDeterministic(t *testing.T) {
+	SetNodeSearchConfiguration(conf.NodeSearchConfiguration{
+		PercentageOfNodesToFind: 10,
+		MinNodesToFind:          int32Ptr(0),
+		NodeWorkers:             16,
+	})
+	defer SetNodeSearchConfiguration(conf.NodeSearchConfiguration{})
+
+	nodes := map[string]*api.NodeInfo{}
+	for i := 0; i < 100; i++ {
+		name := fmt.Sprintf("node%02d", i)
+		nodes[name] = &api.NodeInfo{Name: name}
+	}
+	task := &api.TaskInfo{Namespace: "c1", Name: "p1"}
+	fn := func(task *api.TaskInfo, node *api.NodeInfo) error {
+		var index int
+		fmt.Sscanf(node.Name, "node%d", &index)
+		if index%3 == 0 {
+			return fmt.Errorf("%s is not feasible", node.Name)
+		}
+		return nil
+	}
+
+	// The feasible nodes are taken in the order of search regardless of the
+	// parallel workers, so the searches from the same index give the same result.
+	expected := []string{"node10", "node11", "node13", "node14", "node16", "node17", "node19", "node20", "node22", "node23"}
+	for i := 0; i < 10; i++ {
+		SetLastProcessedNodeIndex(10)
+		predicateNodes, fitErrors := PredicateNodes(task, GetNodeList(nodes), fn)
+
+		var got []string
+		for _, node := range predicateNodes {
+			got = append(got, node.Name)
+		}
+		if !reflect.DeepEqual(got, expected) {
+			t.Fatalf("Failed round #%d, expected: %v, got %v", i, expected, got)
+		}
+		if index := GetLastProcessedNodeIndex(); index != 24 {
+			t.Errorf("Failed round #%d, expected next index 24, got %d", i, index)
+		}
+		if len(fitErrors.NodeReasons()) != 4 {
+			t.Errorf("Failed round #%d, expected 4 fit errors, got %v", i, fitErrors.NodeReasons())
+		}
+	}
+}
@@ -119,7 +119,7 @@ type FakeEvictor struct {
 }
 
 // Evict is used by fake evictor to evict pods
-func (fe *FakeEvictor) Evict(p *v1.Pod) error {
+func (fe *FakeEvictor) Evict(p *v1.Pod, gracePeriodSeconds *int64) error {
 	fe.Lock()
 	defer fe.Unlock()
 
@@ -148,6 +148,12 @@ func (ftsu *FakeStatusUpdater) UpdatePodGroup(pg *kbv1.PodGroup) (*kbv1.PodGroup
 	return nil, nil
 }
 
+// UpdateQueueStatus is a empty function
+func (ftsu *FakeStatusUpdater) UpdateQueueStatus(queue *kbv1.Queue) (*kbv1.Queue, error) {
+	// do nothing here
+	return nil, nil
+}
+
 // FakeVolumeBinder is used as fake volume binder
 type FakeVolumeBinder struct {
 }
@@ -161,3 +167,8 @@ func (fvb *FakeVolumeBinder) AllocateVolumes(task *api.TaskInfo, hostname string
 func (fvb *FakeVolumeBinder) BindVolumes(task *api.TaskInfo) error {
 	return nil
 }
+
+// RevertVolumes is a empty function
+func (fvb *FakeVolumeBinder) RevertVolumes(task *api.TaskInfo) error {
+	return nil
+}
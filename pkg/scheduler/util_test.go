@@ -18,10 +18,12 @@ package scheduler
 
 import (
 	"reflect"
+	"strings"
 	"testing"
 
 	_ "github.com/kubernetes-sigs/kube-batch/pkg/scheduler/actions"
 	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/conf"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/util"
 )
 
 func TestLoadSchedulerConf(t *testing.T) {
@@ -48,6 +50,7 @@ tiers:
 					EnabledJobOrder:     &trueValue,
 					EnabledJobReady:     &trueValue,
 					EnabledJobPipelined: &trueValue,
+					EnabledJobStarving:  &trueValue,
 					EnabledTaskOrder:    &trueValue,
 					EnabledPreemptable:  &trueValue,
 					EnabledReclaimable:  &trueValue,
@@ -60,6 +63,7 @@ tiers:
 					EnabledJobOrder:     &trueValue,
 					EnabledJobReady:     &trueValue,
 					EnabledJobPipelined: &trueValue,
+					EnabledJobStarving:  &trueValue,
 					EnabledTaskOrder:    &trueValue,
 					EnabledPreemptable:  &trueValue,
 					EnabledReclaimable:  &trueValue,
@@ -72,6 +76,7 @@ tiers:
 					EnabledJobOrder:     &trueValue,
 					EnabledJobReady:     &trueValue,
 					EnabledJobPipelined: &trueValue,
+					EnabledJobStarving:  &trueValue,
 					EnabledTaskOrder:    &trueValue,
 					EnabledPreemptable:  &trueValue,
 					EnabledReclaimable:  &trueValue,
@@ -88,6 +93,7 @@ tiers:
 					EnabledJobOrder:     &trueValue,
 					EnabledJobReady:     &trueValue,
 					EnabledJobPipelined: &trueValue,
+					EnabledJobStarving:  &trueValue,
 					EnabledTaskOrder:    &trueValue,
 					EnabledPreemptable:  &trueValue,
 					EnabledReclaimable:  &trueValue,
@@ -100,6 +106,7 @@ tiers:
 					EnabledJobOrder:     &trueValue,
 					EnabledJobReady:     &trueValue,
 					EnabledJobPipelined: &trueValue,
+					EnabledJobStarving:  &trueValue,
 					EnabledTaskOrder:    &trueValue,
 					EnabledPreemptable:  &trueValue,
 					EnabledReclaimable:  &trueValue,
@@ -112,6 +119,7 @@ tiers:
 					EnabledJobOrder:     &trueValue,
 					EnabledJobReady:     &trueValue,
 					EnabledJobPipelined: &trueValue,
+					EnabledJobStarving:  &trueValue,
 					EnabledTaskOrder:    &trueValue,
 					EnabledPreemptable:  &trueValue,
 					EnabledReclaimable:  &trueValue,
@@ -124,6 +132,7 @@ tiers:
 					EnabledJobOrder:     &trueValue,
 					EnabledJobReady:     &trueValue,
 					EnabledJobPipelined: &trueValue,
+					EnabledJobStarving:  &trueValue,
 					EnabledTaskOrder:    &trueValue,
 					EnabledPreemptable:  &trueValue,
 					EnabledReclaimable:  &trueValue,
@@ -135,12 +144,73 @@ tiers:
 		},
 	}
 
-	_, tiers, err := loadSchedulerConf(configuration)
+	_, schedulerConf, err := LoadSchedulerConf(configuration)
 	if err != nil {
-		t.Errorf("Failed to load scheduler configuration: %v", err)
+		t.Fatalf("Failed to load scheduler configuration: %v", err)
 	}
-	if !reflect.DeepEqual(tiers, expectedTiers) {
+	if !reflect.DeepEqual(schedulerConf.Tiers, expectedTiers) {
 		t.Errorf("Failed to set default settings for plugins, expected: %+v, got %+v",
-			expectedTiers, tiers)
+			expectedTiers, schedulerConf.Tiers)
+	}
+
+	minNodes := int32(util.DefaultMinNodesToFind)
+	expectedNodeSearch := conf.NodeSearchConfiguration{
+		PercentageOfNodesToFind: util.DefaultPercentageOfNodesToFind,
+		MinNodesToFind:          &minNodes,
+		NodeWorkers:             util.DefaultNodeWorkers,
+	}
+	if !reflect.DeepEqual(schedulerConf.NodeSearch, expectedNodeSearch) {
+		t.Errorf("Failed to set default settings for searching nodes, expected: %+v, got %+v",
+			expectedNodeSearch, schedulerConf.NodeSearch)
+	}
+}
+
+func TestLoadSchedulerConfNodeSearch(t *testing.T) {
+	configuration := `
+actions: "allocate, backfill"
+percentageOfNodesToFind: 30
+minNodesToFind: 50
+nodeWorkers: 32
+tiers:
+- plugins:
+  - name: priority
+`
+
+	_, schedulerConf, err := LoadSchedulerConf(configuration)
+	if err != nil {
+		t.Fatalf("Failed to load scheduler configuration: %v", err)
+	}
+
+	minNodes := int32(50)
+	expected := conf.NodeSearchConfiguration{
+		PercentageOfNodesToFind: 30,
+		MinNodesToFind:          &minNodes,
+		NodeWorkers:             32,
+	}
+	if !reflect.DeepEqual(schedulerConf.NodeSearch, expected) {
+		t.Errorf("expected: %+v, got %+v", expected, schedulerConf.NodeSearch)
+	}
+
+	noMinimum := `
+actions: "allocate, backfill"
+percentageOfNodesToFind: 30
+minNodesToFind: 0
+`
+	_, schedulerConf, err = LoadSchedulerConf(noMinimum)
+	if err != nil {
+		t.Fatalf("Failed to load scheduler configuration: %v", err)
+	}
+	if schedulerConf.NodeSearch.MinNodesToFind == nil || *schedulerConf.NodeSearch.MinNodesToFind != 0 {
+		t.Errorf("expected minNodesToFind 0 to be kept, got %v", schedulerConf.NodeSearch.MinNodesToFind)
+	}
+
+	invalid := `
+actions: "allocate, backfill"
+percentageOfNodesToFind: 120
+`
+	if _, _, err := LoadSchedulerConf(invalid); err == nil {
+		t.Errorf("expected error for invalid percentageOfNodesToFind, got nil")
+	} else if !strings.Contains(err.Error(), "[0, 100]") {
+		t.Errorf("expected error with accepted range [0, 100], got %v", err)
 	}
 }
@@ -0,0 +1,214 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/conf"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/framework"
+)
+
+// actionOrders are the pairs of actions which must be executed in the given order
+// when both of them are configured.
+var actionOrders = [][2]string{
+	// enqueue moves jobs to Inqueue, which the other actions only handle.
+	{"enqueue", "reclaim"},
+	{"enqueue", "allocate"},
+	{"enqueue", "backfill"},
+	{"enqueue", "preempt"},
+	{"enqueue", "reserve"},
+	// reserve locks the nodes for starving job, which allocate and backfill respect.
+	{"reserve", "allocate"},
+	{"reserve", "backfill"},
+	// backfill only handles tasks which do not request resources, it should
+	// not take nodes before allocate.
+	{"allocate", "backfill"},
+}
+
+// ValidateSchedulerConf validates the scheduler configuration, e.g. the content of
+// the file of --scheduler-conf, against the registered actions and plugins.
+func ValidateSchedulerConf(confStr string) error {
+	_, _, err := LoadSchedulerConf(confStr)
+	return err
+}
+
+// validateSchedulerConf checks the actions, plugins and action configurations of
+// scheduler configuration against the registered actions and plugins; all the errors
+// are returned together.
+func validateSchedulerConf(schedulerConf *conf.SchedulerConfiguration) error {
+	var errs []error
+
+	errs = append(errs, validateActions(schedulerConf.Actions)...)
+	errs = append(errs, validateTiers(schedulerConf.Tiers)...)
+	errs = append(errs, validateConfigurations(schedulerConf.Configurations)...)
+
+	return utilerrors.NewAggregate(errs)
+}
+
+func validateActions(actions string) []error {
+	var errs []error
+
+	index := map[string]int{}
+	for i, name := range strings.Split(actions, ",") {
+		name = strings.TrimSpace(name)
+		if len(name) == 0 {
+			errs = append(errs, fmt.Errorf("actions[%d]: empty action name", i))
+			continue
+		}
+		if _, found := framework.GetAction(name); !found {
+			errs = append(errs, fmt.Errorf("actions[%d]: unknown action <%s>, registered actions: %v",
+				i, name, framework.GetActionNames()))
+			continue
+		}
+		if _, found := index[name]; found {
+			errs = append(errs, fmt.Errorf("actions[%d]: duplicated action <%s>", i, name))
+			continue
+		}
+		index[name] = i
+	}
+
+	for _, order := range actionOrders {
+		before, foundBefore := index[order[0]]
+		after, foundAfter := index[order[1]]
+		if foundBefore && foundAfter && before > after {
+			errs = append(errs, fmt.Errorf("actions[%d]: action <%s> must be configured before <%s>",
+				before, order[0], order[1]))
+		}
+	}
+
+	return errs
+}
+
+func validateTiers(tiers []conf.Tier) []error {
+	var errs []error
+
+	plugins := map[string]string{}
+	for i, tier := range tiers {
+		for j, plugin := range tier.Plugins {
+			path := fmt.Sprintf("tiers[%d].plugins[%d]", i, j)
+
+			if _, found := framework.GetPluginBuilder(plugin.Name); !found {
+				errs = append(errs, fmt.Errorf("%s: unknown plugin <%s>, registered plugins: %v",
+					path, plugin.Name, framework.GetPluginNames()))
+				continue
+			}
+			if prev, found := plugins[plugin.Name]; found {
+				errs = append(errs, fmt.Errorf("%s: plugin <%s> is already configured in %s",
+					path, plugin.Name, prev))
+				continue
+			}
+			plugins[plugin.Name] = path
+
+			errs = append(errs, validatePluginArguments(path, plugin)...)
+		}
+	}
+
+	return errs
+}
+
+func validateConfigurations(configurations []conf.Configuration) []error {
+	var errs []error
+
+	actions := map[string]string{}
+	for i, configuration := range configurations {
+		path := fmt.Sprintf("configurations[%d]", i)
+
+		if _, found := framework.GetAction(configuration.Name); !found {
+			errs = append(errs, fmt.Errorf("%s: unknown action <%s>, registered actions: %v",
+				path, configuration.Name, framework.GetActionNames()))
+			continue
+		}
+		if prev, found := actions[configuration.Name]; found {
+			errs = append(errs, fmt.Errorf("%s: action <%s> is already configured in %s",
+				path, configuration.Name, prev))
+			continue
+		}
+		actions[configuration.Name] = path
+
+		errs = append(errs, validateActionArguments(path, configuration)...)
+	}
+
+	return errs
+}
+
+func validateActionArguments(path string, configuration conf.Configuration) []error {
+	var errs []error
+
+	accepted, found := framework.GetActionArguments(configuration.Name)
+	if !found {
+		return nil
+	}
+
+	var keys []string
+	for key := range configuration.Arguments {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if !acceptArgument(accepted, key) {
+			errs = append(errs, fmt.Errorf("%s.arguments: unknown argument <%s> of action <%s>, accepted arguments: %v",
+				path, key, configuration.Name, accepted))
+		}
+	}
+
+	return errs
+}
+
+func validatePluginArguments(path string, plugin conf.PluginOption) []error {
+	var errs []error
+
+	accepted, found := framework.GetPluginArguments(plugin.Name)
+	if !found {
+		return nil
+	}
+
+	var keys []string
+	for key := range plugin.Arguments {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if !acceptArgument(accepted, key) {
+			errs = append(errs, fmt.Errorf("%s.arguments: unknown argument <%s> of plugin <%s>, accepted arguments: %v",
+				path, key, plugin.Name, accepted))
+		}
+	}
+
+	return errs
+}
+
+func acceptArgument(accepted []string, key string) bool {
+	for _, a := range accepted {
+		if strings.HasSuffix(a, ".") {
+			if strings.HasPrefix(key, a) && len(key) > len(a) {
+				return true
+			}
+			continue
+		}
+		if key == a {
+			return true
+		}
+	}
+	return false
+}
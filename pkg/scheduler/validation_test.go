@@ -0,0 +1,195 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"strings"
+	"testing"
+
+	_ "github.com/kubernetes-sigs/kube-batch/pkg/scheduler/actions"
+)
+
+func TestValidateSchedulerConf(t *testing.T) {
+	tests := []struct {
+		name          string
+		configuration string
+		// the error messages expected, nil for a valid configuration
+		expected []string
+	}{
+		{
+			name:          "default configuration",
+			configuration: defaultSchedulerConf,
+		},
+		{
+			name: "plugin arguments",
+			configuration: `
+actions: "enqueue, reclaim, allocate, backfill, preempt"
+tiers:
+- plugins:
+  - name: binpack
+    arguments:
+      binpack.weight: 10
+      binpack.resources: nvidia.com/gpu
+      binpack.resources.nvidia.com/gpu: 2
+  - name: predicates
+    arguments:
+      predicate.MemoryPressureEnable: true
+`,
+		},
+		{
+			name: "unknown yaml key",
+			configuration: `
+actions: "allocate, backfill"
+tiers:
+- plugins:
+  - name: gang
+    enableJobOrderr: false
+`,
+			expected: []string{"field enableJobOrderr not found"},
+		},
+		{
+			name: "unknown plugin and action",
+			configuration: `
+actions: "allocate, backfil"
+tiers:
+- plugins:
+  - name: gangg
+`,
+			expected: []string{
+				"actions[1]: unknown action <backfil>",
+				"tiers[0].plugins[0]: unknown plugin <gangg>",
+			},
+		},
+		{
+			name: "duplicated action and plugin",
+			configuration: `
+actions: "allocate, allocate"
+tiers:
+- plugins:
+  - name: gang
+- plugins:
+  - name: gang
+`,
+			expected: []string{
+				"actions[1]: duplicated action <allocate>",
+				"tiers[1].plugins[0]: plugin <gang> is already configured in tiers[0].plugins[0]",
+			},
+		},
+		{
+			name: "action order",
+			configuration: `
+actions: "backfill, allocate, enqueue"
+tiers:
+- plugins:
+  - name: gang
+`,
+			expected: []string{
+				"actions[2]: action <enqueue> must be configured before <allocate>",
+				"actions[1]: action <allocate> must be configured before <backfill>",
+			},
+		},
+		{
+			name: "unknown argument",
+			configuration: `
+actions: "allocate"
+tiers:
+- plugins:
+  - name: gang
+    arguments:
+      gang.weight: 1
+  - name: nodeorder
+    arguments:
+      nodeaffinity.wieght: 2
+  - name: binpack
+    arguments:
+      binpack.resources.: 2
+`,
+			expected: []string{
+				"tiers[0].plugins[0].arguments: unknown argument <gang.weight>",
+				"tiers[0].plugins[1].arguments: unknown argument <nodeaffinity.wieght>",
+				"tiers[0].plugins[2].arguments: unknown argument <binpack.resources.>",
+			},
+		},
+		{
+			name: "action configurations",
+			configuration: `
+actions: "allocate, preempt"
+tiers:
+- plugins:
+  - name: gang
+configurations:
+- name: preempt
+  arguments:
+    preempt.crossQueuePriority: 1000
+- name: preempt
+- name: preemptt
+- name: allocate
+`,
+			expected: []string{
+				"configurations[1]: action <preempt> is already configured in configurations[0]",
+				"configurations[2]: unknown action <preemptt>",
+			},
+		},
+		{
+			name: "unknown action argument",
+			configuration: `
+actions: "preempt"
+tiers:
+- plugins:
+  - name: gang
+configurations:
+- name: preempt
+  arguments:
+    preempt.crossQueuePriorty: 1000
+`,
+			expected: []string{"configurations[0].arguments: unknown argument <preempt.crossQueuePriorty> of action <preempt>"},
+		},
+		{
+			name: "argument of action without arguments",
+			configuration: `
+actions: "allocate, backfill"
+tiers:
+- plugins:
+  - name: gang
+configurations:
+- name: backfill
+  arguments:
+    backfill.nodeOrder: true
+`,
+			expected: []string{"configurations[0].arguments: unknown argument <backfill.nodeOrder> of action <backfill>"},
+		},
+	}
+
+	for _, test := range tests {
+		err := ValidateSchedulerConf(test.configuration)
+		if len(test.expected) == 0 {
+			if err != nil {
+				t.Errorf("case <%s>: expected no error, got %v", test.name, err)
+			}
+			continue
+		}
+		if err == nil {
+			t.Errorf("case <%s>: expected errors %v, got nil", test.name, test.expected)
+			continue
+		}
+		for _, msg := range test.expected {
+			if !strings.Contains(err.Error(), msg) {
+				t.Errorf("case <%s>: expected error <%s>, got %v", test.name, msg, err)
+			}
+		}
+	}
+}